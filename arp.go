@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"fmt"
+	"sort"
+	"github.com/mikejac/ssh.golang/parser"
+)
+
+// The ARP types are defined, and parsed, in the parser package
+type ArpEntry	= parser.ArpEntry
+type ArpTable	= parser.ArpTable
+
+//
+// 3600
+func (sshAction *SshAction) GetARP() (arp ArpTable, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetARP(): begin\n") }
+
+	var result		string
+	var proxy		bool
+	var warnings	parser.Warnings
+
+	switch sshAction.platform {
+		case PlatformGAiA:
+			fallthrough
+
+		case PlatformGaiaEmbedded:
+			fallthrough
+
+		case PlatformScalable:
+			fallthrough
+
+		case PlatformSplatCPSHELL:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetARP(): PlatformGAiA, PlatformGaiaEmbedded, PlatformScalable or PlatformSplatCPSHELL\n") }
+
+			if err = sshAction.expertEnter(); err == nil {
+				if result, err = sshAction.execute("ip neigh show 2>&1", 10); err == nil {
+					arp, warnings = parser.ParseIPNeigh(result)
+					err = sshAction.proxyARP(&arp)
+				}
+
+				sshAction.expertExit()
+			}
+
+		case PlatformExpert:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetARP(): PlatformExpert\n") }
+
+			if result, err = sshAction.execute("ip neigh show 2>&1", 10); err == nil {
+				arp, warnings = parser.ParseIPNeigh(result)
+				err = sshAction.proxyARP(&arp)
+			}
+
+		case PlatformIPSO:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetARP(): PlatformIPSO\n") }
+
+			if result, err = sshAction.execute("arp -an", 10); err == nil {
+				arp, warnings = parser.ParseArpAn(result)
+				err = sshAction.proxyARP(&arp)
+			}
+
+		case PlatformXBM:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetARP(): PlatformXBM\n") }
+
+			if result, err = sshAction.execute("show arp", 10); err == nil {
+				arp = parser.ParseXOSArp(result)
+			}
+
+		default:
+			fmt.Printf("SshAction::GetARP(): unknown platform\n")
+			return nil, New(3601, "platform unknown")
+	}
+
+	sshAction.warn(warnings)
+
+	if err != nil {
+		return nil, New(3602, err.Error())
+	}
+
+	for _, a := range arp {
+		if a.Proxy {
+			proxy = true
+			break
+		}
+	}
+
+	sort.Sort(arp)
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetARP(): end; %d entries, proxy = %v\n", len(arp), proxy) }
+
+	return arp, nil
+}
+
+//
+// proxyARP appends the manual proxy ARP entries of local.arp and those the
+// kernel module actually answers for ('fw ctl arp', which includes automatic
+// NAT entries). Must be called from the expert shell.
+func (sshAction *SshAction) proxyARP(arp *ArpTable) (err error) {
+	var result string
+
+	if result, err = sshAction.execute("cat $FWDIR/conf/local.arp 2>/dev/null", 10); err != nil {
+		return err
+	}
+
+	local, warnings := parser.ParseLocalArp(result)
+
+	*arp = append(*arp, local...)
+
+	sshAction.warn(warnings)
+
+	if result, err = sshAction.execute("fw ctl arp 2>&1", 10); err != nil {
+		return err
+	}
+
+	*arp = append(*arp, parser.ParseFwCtlArp(result)...)
+
+	return nil
+}
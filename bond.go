@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"fmt"
+	"sort"
+	"github.com/mikejac/ssh.golang/parser"
+)
+
+// The bond and bridge types are defined, and parsed, in the parser package
+type BondSlave		= parser.BondSlave
+type NetworkBond		= parser.NetworkBond
+type Bonds			= parser.Bonds
+type NetworkBridge	= parser.NetworkBridge
+type Bridges			= parser.Bridges
+
+const (
+	bondingCmd	= `for f in /proc/net/bonding/*; do [ -f "$f" ] && echo "== $(basename $f)" && cat "$f"; done`
+	bridgeCmd	= `for b in /sys/class/net/*/brif; do [ -d "$b" ] && echo "== $(basename $(dirname $b))" && ls "$b"; done`
+)
+
+//
+// 3400
+func (sshAction *SshAction) GetBonds() (bonds Bonds, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetBonds(): begin\n") }
+
+	result, err := sshAction.linuxExecute("GetBonds", bondingCmd)
+	if err != nil {
+		return nil, err
+	}
+
+	bonds = parser.ParseProcBonding(result)
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetBonds(): end; %d bonds\n", len(bonds)) }
+
+	return bonds, nil
+}
+
+//
+// 3400
+func (sshAction *SshAction) GetBridges() (bridges Bridges, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetBridges(): begin\n") }
+
+	result, err := sshAction.linuxExecute("GetBridges", bridgeCmd)
+	if err != nil {
+		return nil, err
+	}
+
+	bridges = parser.ParseBridgeMembers(result)
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetBridges(): end; %d bridges\n", len(bridges)) }
+
+	return bridges, nil
+}
+
+//
+// linuxExecute runs cmd in the expert shell of the Linux based platforms
+func (sshAction *SshAction) linuxExecute(caller string, cmd string) (result string, err error) {
+	switch sshAction.platform {
+		case PlatformGAiA, PlatformGaiaEmbedded, PlatformScalable, PlatformSplatCPSHELL, PlatformExpert:
+			result, err = sshAction.expertExecute(cmd, 20)
+			if err != nil {
+				if sshAction.verbose > 0 { fmt.Printf("SshAction::%s(): unable to execute '%s'\n", caller, cmd) }
+				return "", New(3402, err.Error())
+			}
+
+		case PlatformIPSO:
+			fallthrough
+
+		case PlatformXBM:
+			return "", New(3400, "not supported on platform")
+
+		default:
+			fmt.Printf("SshAction::%s(): unknown platform\n", caller)
+			return "", New(3401, "platform unknown")
+	}
+
+	return result, nil
+}
+
+//
+// LinkMembership marks bond slaves and bridge ports with their master and
+// lists the members of each bond and bridge. Members not yet in physical,
+// typically slave NICs without an address, are added.
+func LinkMembership(physical PhysicalInterfaces, bonds Bonds, bridges Bridges) (PhysicalInterfaces) {
+	link := func(master string, kind string, members []string) {
+		for _, m := range members {
+			found := false
+
+			for i := range physical {
+				if physical[i].IfName == m && physical[i].VLAN == "" {
+					physical[i].Master = master
+					found = true
+				}
+			}
+
+			if !found {
+				physical = append(physical, NetworkPhysicalInterface{IfName: m, Master: master})
+			}
+		}
+
+		found := false
+
+		for i := range physical {
+			if physical[i].IfName == master {
+				physical[i].Kind = kind
+
+				if physical[i].VLAN == "" {
+					physical[i].Members = members
+					found = true
+				}
+			}
+		}
+
+		if !found {
+			physical = append(physical, NetworkPhysicalInterface{IfName: master, Kind: kind, Members: members})
+		}
+	}
+
+	for _, b := range bonds {
+		var members []string
+
+		for _, s := range b.Slaves {
+			members = append(members, s.IfName)
+		}
+
+		link(b.IfName, "bond", members)
+	}
+
+	for _, b := range bridges {
+		link(b.IfName, "bridge", b.Members)
+	}
+
+	sort.Sort(physical)
+
+	return physical
+}
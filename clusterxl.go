@@ -0,0 +1,222 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	failoverPollInterval	int = 2
+)
+
+// FailoverReport describes a clusterXL_admin run and the cluster state before
+// and after it
+type FailoverReport struct {
+	Action			string				// "down" or "up"
+	Persistent		bool
+	Output			string				// clusterXL_admin output
+	Before			*CphaData
+	After			*CphaData
+	Active			[]CphaMember		// members active once the change settled
+	Duration		time.Duration
+	Completed		bool				// false when the wait timed out
+}
+
+//
+// ClusterMemberDown takes the local member out of the cluster with
+// 'clusterXL_admin down' and waits up to timeout seconds for a peer to become
+// active. It refuses to run unless a peer is healthy enough to take over.
+func (sshAction *SshAction) ClusterMemberDown(persistent bool, timeout int) (report *FailoverReport, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::ClusterMemberDown(): begin\n") }
+
+	report = &FailoverReport{Action: "down", Persistent: persistent}
+
+	if report.Before, err = sshAction.GetCPHA(); err != nil {
+		return nil, err
+	}
+
+	local := localMember(report.Before)
+	if local == nil {
+		return nil, New(4100, "local member not found, clustering not started")
+	}
+
+	if local.State == "down" {
+		return nil, New(4101, "local member is already down")
+	}
+
+	if healthyPeers(report.Before) == 0 {
+		return nil, New(4102, "no healthy peer to take over")
+	}
+
+	if err = sshAction.clusterXLAdmin(report); err != nil {
+		return report, err
+	}
+
+	err = sshAction.waitFailover(report, timeout, func(cpha *CphaData) (bool) {
+		l := localMember(cpha)
+
+		if l == nil || l.State != "down" {
+			return false
+		}
+
+		return len(activeMembers(cpha, false)) > 0
+	})
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::ClusterMemberDown(): end\n") }
+
+	return report, err
+}
+
+//
+// ClusterMemberUp returns the local member to the cluster with
+// 'clusterXL_admin up' and waits up to timeout seconds for it to leave the
+// down state
+func (sshAction *SshAction) ClusterMemberUp(persistent bool, timeout int) (report *FailoverReport, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::ClusterMemberUp(): begin\n") }
+
+	report = &FailoverReport{Action: "up", Persistent: persistent}
+
+	if report.Before, err = sshAction.GetCPHA(); err != nil {
+		return nil, err
+	}
+
+	if localMember(report.Before) == nil {
+		return nil, New(4100, "local member not found, clustering not started")
+	}
+
+	if err = sshAction.clusterXLAdmin(report); err != nil {
+		return report, err
+	}
+
+	err = sshAction.waitFailover(report, timeout, func(cpha *CphaData) (bool) {
+		l := localMember(cpha)
+
+		return l != nil && (l.State == "active" || l.State == "standby")
+	})
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::ClusterMemberUp(): end\n") }
+
+	return report, err
+}
+
+//
+//
+func (sshAction *SshAction) clusterXLAdmin(report *FailoverReport) (err error) {
+	cmd := "clusterXL_admin " + report.Action
+
+	if report.Persistent {
+		cmd += " -p"
+	}
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::clusterXLAdmin(): cmd = '%s'\n", cmd) }
+
+	report.Output, err = sshAction.expertExecute(cmd + " 2>&1", 30)
+	if err != nil {
+		return New(4103, err.Error())
+	}
+
+	report.Output = strings.TrimSpace(report.Output)
+
+	if strings.Contains(report.Output, "command not found") || strings.Contains(report.Output, "Usage") {
+		return New(4104, report.Output)
+	}
+
+	return nil
+}
+
+//
+// waitFailover polls GetCPHA until settled reports true or timeout seconds
+// have passed
+func (sshAction *SshAction) waitFailover(report *FailoverReport, timeout int, settled func(*CphaData) (bool)) (err error) {
+	start    := time.Now()
+	deadline := start.Add(time.Duration(timeout) * time.Second)
+
+	for {
+		time.Sleep(time.Duration(failoverPollInterval) * time.Second)
+
+		if report.After, err = sshAction.GetCPHA(); err != nil {
+			return err
+		}
+
+		if settled(report.After) {
+			report.Completed = true
+			break
+		}
+
+		if time.Now().After(deadline) {
+			break
+		}
+	}
+
+	report.Duration = time.Since(start)
+	report.Active   = activeMembers(report.After, true)
+
+	if !report.Completed {
+		return New(4105, "timeout waiting for cluster state change")
+	}
+
+	return nil
+}
+
+/******************************************************************************************************************
+* helper functions
+*
+*/
+
+//
+//
+func localMember(cpha *CphaData) (*CphaMember) {
+	for i := range cpha.Members {
+		if cpha.Members[i].Local {
+			return &cpha.Members[i]
+		}
+	}
+
+	return nil
+}
+
+//
+//
+func activeMembers(cpha *CphaData, includeLocal bool) (active []CphaMember) {
+	for _, m := range cpha.Members {
+		if m.State == "active" && (includeLocal || !m.Local) {
+			active = append(active, m)
+		}
+	}
+
+	return active
+}
+
+//
+// healthyPeers counts the remote members able to carry traffic
+func healthyPeers(cpha *CphaData) (n int) {
+	for _, m := range cpha.Members {
+		if !m.Local && (m.State == "active" || m.State == "standby") {
+			n++
+		}
+	}
+
+	return n
+}
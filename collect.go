@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"fmt"
+	"sync"
+	"github.com/mikejac/ssh.golang/parser"
+)
+
+// Template output, see parser.Template
+type Record	= parser.Record
+type Records	= parser.Records
+
+//
+// Collector is a user defined command and the TextFSM template that parses
+// its output on one platform
+type Collector struct {
+	Command		string
+	Clish		bool					// run in CLISH rather than the expert shell
+	Timeout		int						// seconds, 20 when 0
+	Template	*parser.Template
+}
+
+var collectors struct {
+	sync.RWMutex
+	
+	m	map[string]map[Platform]Collector
+}
+
+//
+// 9100
+// RegisterCollector compiles template and registers command under name for
+// each of platforms, replacing earlier registrations
+func RegisterCollector(name string, platforms []Platform, command string, clish bool, template string) (err error) {
+	t, err := parser.ParseTemplate(template)
+	if err != nil {
+		return New(9100, err.Error())
+	}
+	
+	return RegisterCollectorWith(name, platforms, Collector{Command: command, Clish: clish, Template: t})
+}
+
+//
+// 9100
+// RegisterCollectorWith registers c under name for each of platforms,
+// replacing earlier registrations; unlike RegisterCollector it takes a
+// Timeout, for slow commands like cpinfo or fw tab
+func RegisterCollectorWith(name string, platforms []Platform, c Collector) (err error) {
+	if c.Template == nil {
+		return New(9105, "no template")
+	}
+	
+	if c.Timeout < 0 {
+		return New(9106, "negative timeout")
+	}
+	
+	collectors.Lock()
+	defer collectors.Unlock()
+	
+	if collectors.m == nil {
+		collectors.m = make(map[string]map[Platform]Collector)
+	}
+	
+	if collectors.m[name] == nil {
+		collectors.m[name] = make(map[Platform]Collector)
+	}
+	
+	for _, p := range platforms {
+		collectors.m[name][p] = c
+	}
+	
+	return nil
+}
+
+//
+// 9100
+// Collect runs the collector registered as name for the platform of this
+// gateway and returns the template's records
+func (sshAction *SshAction) Collect(name string) (records Records, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::Collect(): begin; name = '%s'\n", name) }
+	
+	collectors.RLock()
+	c, ok := collectors.m[name][sshAction.platform]
+	collectors.RUnlock()
+	
+	if !ok {
+		return nil, New(9101, "no collector '" + name + "' for platform")
+	}
+	
+	timeout := c.Timeout
+	
+	if timeout == 0 {
+		timeout = 20
+	}
+	
+	var result string
+	
+	switch {
+		case c.Clish:
+			result, err = sshAction.clishExecute(c.Command, timeout)
+			
+		case sshAction.platform == PlatformXBM:
+			result, err = sshAction.execute(c.Command, timeout)
+			
+		default:
+			result, err = sshAction.expertExecute(c.Command, timeout)
+	}
+	
+	if err != nil {
+		if sshAction.verbose > 0 { fmt.Printf("SshAction::Collect(): unable to execute '%s'\n", c.Command) }
+		return nil, New(9102, err.Error())
+	}
+	
+	records, err = c.Template.Parse(result)
+	if err != nil {
+		return nil, New(9103, err.Error())
+	}
+	
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::Collect(): end; %d records\n", len(records)) }
+	
+	return records, nil
+}
+
+//
+// 9100
+// CollectInto runs Collect and decodes the records into out, a pointer to a
+// slice of structs, see parser.Records.Decode
+func (sshAction *SshAction) CollectInto(name string, out interface{}) (err error) {
+	records, err := sshAction.Collect(name)
+	if err != nil {
+		return err
+	}
+	
+	if err = records.Decode(out); err != nil {
+		return New(9104, err.Error())
+	}
+	
+	return nil
+}
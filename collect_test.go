@@ -0,0 +1,252 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"github.com/mikejac/ssh.golang/parser"
+)
+
+const stubPrompt = "[Expert@gw-test:0]# "
+
+// stubShell stands in for the remote shell of an SshAction: every command
+// written to it is echoed, followed by its canned output and the prompt
+type stubShell struct {
+	outputs		map[string]string			// keyed by command
+	commands	[]string
+	w			*io.PipeWriter
+}
+
+//
+// newStubSession returns an SshAction on platform talking to a stubShell
+func newStubSession(platform Platform, outputs map[string]string) (sshAction *SshAction, shell *stubShell) {
+	r, w := io.Pipe()
+
+	shell = &stubShell{outputs: outputs, w: w}
+
+	sshAction = &SshAction{
+		in:				shell,
+		out:			r,
+		currentPrompt:	stubPrompt,
+		platform:		platform,
+	}
+
+	return sshAction, shell
+}
+
+//
+//
+func (shell *stubShell) Write(p []byte) (int, error) {
+	cmd := strings.TrimSuffix(string(p), "\n")
+
+	shell.commands = append(shell.commands, cmd)
+
+	go shell.w.Write([]byte(string(p) + shell.outputs[cmd] + stubPrompt))
+
+	return len(p), nil
+}
+
+//
+//
+func (shell *stubShell) Close() (error) {
+	return shell.w.Close()
+}
+
+//
+// errorNumber returns the number of an SshError, -1 for other errors
+func errorNumber(err error) (int) {
+	if e, ok := err.(*SshError); ok {
+		return e.number
+	}
+
+	return -1
+}
+
+const bondTemplate = `Value Required Name (\S+)
+Value Mode (.+)
+Value List Slaves (\S+)
+
+Start
+  ^== ${Name}
+  ^Bonding Mode: ${Mode}
+  ^Slave Interface: ${Slaves}
+  ^$$ -> Record
+`
+
+const bondOutput = "== bond0\nBonding Mode: fault-tolerance (active-backup)\nSlave Interface: eth1\nSlave Interface: eth2\n\n== bond1\nBonding Mode: round-robin\n\n"
+
+//
+//
+func TestCollect(t *testing.T) {
+	if err := RegisterCollector("test-bonds", []Platform{PlatformExpert, PlatformIPSO}, "cat /proc/net/bonding/*", false, bondTemplate); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RegisterCollector("test-bonds", []Platform{PlatformXBM}, "show bonds", false, bondTemplate); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RegisterCollector("test-clish", []Platform{PlatformExpert}, "show bonds", true, bondTemplate); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RegisterCollector("test-clish-quotes", []Platform{PlatformExpert}, `show bonds | grep -v "it's"`, true, bondTemplate); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		platform	Platform
+		name		string
+		command		string
+	}{
+		{PlatformExpert, "test-bonds", "cat /proc/net/bonding/*"},
+		{PlatformIPSO, "test-bonds", "cat /proc/net/bonding/*"},
+		{PlatformXBM, "test-bonds", "show bonds"},
+		{PlatformExpert, "test-clish", "clish -c 'show bonds' 2>&1"},
+		{PlatformExpert, "test-clish-quotes", `clish -c 'show bonds | grep -v "it'\''s"' 2>&1`},
+	} {
+		sshAction, shell := newStubSession(tt.platform, map[string]string{tt.command: bondOutput})
+
+		records, err := sshAction.Collect(tt.name)
+		if err != nil {
+			t.Fatalf("%s on platform %d: %s", tt.name, tt.platform, err.Error())
+		}
+
+		if len(shell.commands) != 1 || shell.commands[0] != tt.command {
+			t.Errorf("%s on platform %d ran %q, want %q", tt.name, tt.platform, shell.commands, tt.command)
+		}
+
+		if len(records) != 2 || records[0]["Name"] != "bond0" || records[0]["Slaves"] != "eth1\neth2" || records[1]["Mode"] != "round-robin" {
+			t.Errorf("%s on platform %d: records = %v", tt.name, tt.platform, records)
+		}
+
+		shell.Close()
+	}
+}
+
+//
+//
+func TestCollectInto(t *testing.T) {
+	if err := RegisterCollector("test-bonds-into", []Platform{PlatformExpert}, "cat /proc/net/bonding/*", false, bondTemplate); err != nil {
+		t.Fatal(err)
+	}
+
+	sshAction, shell := newStubSession(PlatformExpert, map[string]string{"cat /proc/net/bonding/*": bondOutput})
+	defer shell.Close()
+
+	var bonds []struct {
+		Name	string
+		Mode	string
+		Slaves	[]string
+	}
+
+	if err := sshAction.CollectInto("test-bonds-into", &bonds); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bonds) != 2 || bonds[0].Name != "bond0" || len(bonds[0].Slaves) != 2 || bonds[1].Slaves != nil {
+		t.Errorf("bonds = %+v", bonds)
+	}
+}
+
+//
+//
+func TestCollectErrors(t *testing.T) {
+	if err := RegisterCollector("test-bad", []Platform{PlatformExpert}, "true", false, "Value A (x)\n"); errorNumber(err) != 9100 {
+		t.Errorf("RegisterCollector with a bad template = %v, want 9100", err)
+	}
+
+	if err := RegisterCollector("test-errors", []Platform{PlatformExpert, PlatformCPM}, "cat log", false, "Value Line (.*)\n\nStart\n  ^fatal -> Error\n  ^${Line} -> Record\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		platform	Platform
+		name		string
+		output		string
+		into		interface{}
+		want		int
+	}{
+		{PlatformExpert, "test-missing", "", nil, 9101},
+		{PlatformIPSO, "test-errors", "", nil, 9101},
+		{PlatformCPM, "test-errors", "", nil, 9102},
+		{PlatformExpert, "test-errors", "ok\nfatal\n", nil, 9103},
+		{PlatformExpert, "test-errors", "ok\n", &[]struct{ Line int }{}, 9104},
+	} {
+		sshAction, shell := newStubSession(tt.platform, map[string]string{"cat log": tt.output})
+
+		var err error
+
+		if tt.into != nil {
+			err = sshAction.CollectInto(tt.name, tt.into)
+		} else {
+			_, err = sshAction.Collect(tt.name)
+		}
+
+		if errorNumber(err) != tt.want {
+			t.Errorf("%s on platform %d = %v, want %d", tt.name, tt.platform, err, tt.want)
+		}
+
+		shell.Close()
+	}
+}
+
+//
+//
+func TestRegisterCollectorWith(t *testing.T) {
+	template, err := parser.ParseTemplate(bondTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RegisterCollectorWith("test-with", []Platform{PlatformExpert}, Collector{Command: "cpinfo -y all", Timeout: 1, Template: template}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RegisterCollectorWith("test-with", []Platform{PlatformExpert}, Collector{Command: "true"}); errorNumber(err) != 9105 {
+		t.Errorf("RegisterCollectorWith without a template = %v, want 9105", err)
+	}
+
+	if err := RegisterCollectorWith("test-with", []Platform{PlatformExpert}, Collector{Command: "true", Timeout: -1, Template: template}); errorNumber(err) != 9106 {
+		t.Errorf("RegisterCollectorWith with a negative timeout = %v, want 9106", err)
+	}
+
+	// a shell that never comes back to its prompt
+	sshAction := newPromptSession("", "", nil)
+	sshAction.platform      = PlatformExpert
+	sshAction.currentPrompt = stubPrompt
+
+	start := time.Now()
+
+	if _, err := sshAction.Collect("test-with"); errorNumber(err) != 9102 {
+		t.Errorf("Collect = %v, want 9102", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5 * time.Second {
+		t.Errorf("Collect gave up after %s, want the collector's 1s", elapsed)
+	}
+
+	sshAction.in.Close()
+}
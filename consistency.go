@@ -0,0 +1,318 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConsistencyIssue is one difference between cluster members. Values holds
+// what each member reported, keyed by host; "" means the item is missing.
+type ConsistencyIssue struct {
+	Kind		string				// "route", "interface", "vlan", "version", "take", "mode", "state", "pnote", "sync" or "collect"
+	Item		string				// e.g. the route's network or the interface name
+	Values		map[string]string
+	Message		string
+}
+
+// ConsistencyReport lists the differences found between cluster members
+type ConsistencyReport struct {
+	Members		[]string
+	Issues		[]ConsistencyIssue
+}
+
+// memberState is what is collected from each member
+type memberState struct {
+	host		string
+	logical		LogicalInterfaces
+	physical	PhysicalInterfaces
+	routes		Routes
+	info		*VersionInfo
+	cpha		*CphaData
+}
+
+//
+// CompareClusterMembers collects interfaces, routes, version and ClusterXL
+// state from every member and reports where they differ. A collector failing
+// on one member is reported as an issue of kind "collect".
+func CompareClusterMembers(members []*SshAction) (report *ConsistencyReport, err error) {
+	if len(members) < 2 {
+		return nil, New(4200, "at least two members required")
+	}
+
+	report = &ConsistencyReport{}
+
+	var states []*memberState
+
+	for _, m := range members {
+		if m.verbose > 0 { fmt.Printf("SshAction::CompareClusterMembers(): collecting from '%s'\n", m.host) }
+
+		s := &memberState{host: m.host}
+
+		collectErr := func(what string, err error) {
+			report.Issues = append(report.Issues, ConsistencyIssue{Kind: "collect", Item: what, Values: map[string]string{s.host: err.Error()}, Message: "unable to collect " + what})
+		}
+
+		if s.logical, err = m.GetInterfaces(); err != nil {
+			collectErr("interfaces", err)
+		} else if s.physical, err = m.GetPhyInterfaces(s.logical); err != nil {
+			collectErr("vlans", err)
+		}
+
+		if s.routes, err = m.GetRoutes(); err != nil {
+			collectErr("routes", err)
+		}
+
+		if s.info, err = m.GetInfo(); err != nil {
+			collectErr("version", err)
+		}
+
+		if s.cpha, err = m.GetCPHA(); err != nil {
+			collectErr("cpha", err)
+		}
+
+		report.Members = append(report.Members, s.host)
+		states = append(states, s)
+	}
+
+	report.compare("route", "routes differ", states, func(s *memberState) (map[string]string) {
+		if s.routes == nil {
+			return nil
+		}
+
+		hops := make(map[string][]string)
+
+		for _, r := range s.routes {
+			hops[r.Net] = append(hops[r.Net], r.Gateway + "@" + r.Dev)
+		}
+
+		return joinSorted(hops)
+	})
+
+	// members have addresses of their own, so compare the networks
+	report.compare("interface", "interface networks differ", states, func(s *memberState) (map[string]string) {
+		if s.logical == nil {
+			return nil
+		}
+
+		nets := make(map[string][]string)
+
+		for _, i := range s.logical {
+			if i.Addr != nil && i.Mask != nil {
+				n := net.IPNet{IP: i.Addr.Mask(i.Mask), Mask: i.Mask}
+				nets[i.IfName] = append(nets[i.IfName], n.String())
+			}
+		}
+
+		return joinSorted(nets)
+	})
+
+	report.compare("vlan", "VLANs differ", states, func(s *memberState) (map[string]string) {
+		if s.physical == nil {
+			return nil
+		}
+
+		vlans := make(map[string][]string)
+
+		for _, p := range s.physical {
+			if p.VLAN != "" {
+				vlans[p.IfName] = append(vlans[p.IfName], strings.TrimPrefix(p.OuterVLAN + "." + p.VLAN, "."))
+			}
+		}
+
+		return joinSorted(vlans)
+	})
+
+	report.compare("version", "versions differ", states, func(s *memberState) (map[string]string) {
+		if s.info == nil {
+			return nil
+		}
+
+		return map[string]string{"version": s.info.Version}
+	})
+
+	report.compare("take", "Jumbo Hotfix takes differ", states, func(s *memberState) (map[string]string) {
+		if s.info == nil {
+			return nil
+		}
+
+		return map[string]string{"take": strconv.Itoa(s.info.Take)}
+	})
+
+	report.compare("mode", "cluster modes differ", states, func(s *memberState) (map[string]string) {
+		if s.cpha == nil {
+			return nil
+		}
+
+		return map[string]string{"mode": s.cpha.Mode}
+	})
+
+	report.checkStates(states)
+
+	if members[0].verbose > 0 { fmt.Printf("SshAction::CompareClusterMembers(): %d issues\n", len(report.Issues)) }
+
+	return report, nil
+}
+
+//
+// Consistent reports whether no differences were found
+func (report *ConsistencyReport) Consistent() (bool) {
+	return len(report.Issues) == 0
+}
+
+//
+//
+func (report *ConsistencyReport) String() (string) {
+	var lines []string
+
+	for _, i := range report.Issues {
+		line := i.Kind + " " + i.Item + ": " + i.Message
+
+		for _, h := range report.Members {
+			if v, ok := i.Values[h]; ok {
+				if v == "" {
+					v = "(missing)"
+				}
+
+				line += "; " + h + " = " + v
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+/******************************************************************************************************************
+* helper functions
+*
+*/
+
+//
+// compare adds an issue for every key whose value is not the same on all
+// members that could be collected; get returns nil for those that could not
+func (report *ConsistencyReport) compare(kind string, message string, states []*memberState, get func(*memberState) (map[string]string)) {
+	values := make(map[string]map[string]string)
+	keys   := make(map[string]bool)
+
+	for _, s := range states {
+		if v := get(s); v != nil {
+			values[s.host] = v
+
+			for k := range v {
+				keys[k] = true
+			}
+		}
+	}
+
+	var sorted []string
+
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		issue := ConsistencyIssue{Kind: kind, Item: k, Values: make(map[string]string), Message: message}
+		differ := false
+		first  := true
+		var ref string
+
+		for _, s := range states {
+			v, ok := values[s.host]
+			if !ok {
+				continue
+			}
+
+			issue.Values[s.host] = v[k]
+
+			if first {
+				ref   = v[k]
+				first = false
+			} else if v[k] != ref {
+				differ = true
+			}
+		}
+
+		if differ {
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+}
+
+//
+// checkStates flags members in an unexpected ClusterXL state: anything but
+// active or standby, active pnotes, sync problems, and a High Availability
+// cluster with other than one active member
+func (report *ConsistencyReport) checkStates(states []*memberState) {
+	active := make(map[string]string)
+	ha     := false
+
+	for _, s := range states {
+		if s.cpha == nil {
+			continue
+		}
+
+		state := strings.ToLower(s.cpha.Status)
+
+		if state != "active" && state != "standby" {
+			report.Issues = append(report.Issues, ConsistencyIssue{Kind: "state", Item: "status", Values: map[string]string{s.host: state}, Message: "unexpected member state"})
+		}
+
+		if len(s.cpha.ActivePnotes) > 0 {
+			report.Issues = append(report.Issues, ConsistencyIssue{Kind: "pnote", Item: "active pnotes", Values: map[string]string{s.host: strings.Join(s.cpha.ActivePnotes, ", ")}, Message: "critical devices reporting problem"})
+		}
+
+		if s.cpha.SyncStatus != "" && s.cpha.SyncStatus != "ok" {
+			report.Issues = append(report.Issues, ConsistencyIssue{Kind: "sync", Item: "sync", Values: map[string]string{s.host: s.cpha.SyncStatus}, Message: "state synchronisation problem"})
+		}
+
+		if state == "active" {
+			active[s.host] = state
+		}
+
+		ha = ha || s.cpha.HA
+	}
+
+	if ha && len(active) != 1 {
+		report.Issues = append(report.Issues, ConsistencyIssue{Kind: "state", Item: "active members", Values: active, Message: fmt.Sprintf("%d active members in a High Availability cluster", len(active))})
+	}
+}
+
+//
+//
+func joinSorted(m map[string][]string) (v map[string]string) {
+	v = make(map[string]string)
+
+	for k, l := range m {
+		sort.Strings(l)
+		v[k] = strings.Join(l, ",")
+	}
+
+	return v
+}
@@ -24,31 +24,33 @@ package sshtool
 import (
 	"fmt"
 	"strings"
+	"github.com/mikejac/ssh.golang/parser"
 )
 
-type CphaData struct {
-	Status	string
-}
+// The ClusterXL types are defined, and parsed, in the parser package
+type CphaMember				= parser.CphaMember
+type CphaDevice				= parser.CphaDevice
+type CphaInterface			= parser.CphaInterface
+type CphaVirtualInterface	= parser.CphaVirtualInterface
+type CphaData				= parser.CphaData
 
 func (sshAction *SshAction) GetCPHA() (cpha *CphaData, err error) {
 	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): begin\n") }
 	
 	cpha = &CphaData{}
 	
-	var result string
-	
 	switch sshAction.platform {
 		case PlatformGAiA:
 			fallthrough
 			
+		case PlatformGaiaEmbedded:
+			fallthrough
+			
 		case PlatformSplatCPSHELL:
-			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): PlatformGAiA or PlatformSplatCPSHELL\n") }
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): PlatformGAiA, PlatformGaiaEmbedded or PlatformSplatCPSHELL\n") }
 					
 			if sshAction.expertEnter() == nil {
-				result, err = sshAction.execute("cphaprob stat 2>&1", 10)
-				if err != nil {
-					if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): unable to execute 'cphaprob stat 2>&1'\n") }
-				}
+				err = sshAction.cphaCollect(cpha, " 2>&1")
 			
 				sshAction.expertExit()
 			}
@@ -56,18 +58,21 @@ func (sshAction *SshAction) GetCPHA() (cpha *CphaData, err error) {
 		case PlatformExpert:
 			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): PlatformExpert\n") }
 
-			result, err = sshAction.execute("cphaprob stat 2>&1", 10)
-			if err != nil {
-				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): unable to execute 'cphaprob stat 2>&1'\n") }
-			}
+			err = sshAction.cphaCollect(cpha, " 2>&1")
 		
 		case PlatformIPSO:
 			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): PlatformIPSO\n") }
 
-			result, err = sshAction.execute("cphaprob stat", 10)
-			if err != nil {
-				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): unable to execute 'cphaprob stat'\n") }
+			err = sshAction.cphaCollect(cpha, "")
+		
+		case PlatformScalable:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): PlatformScalable\n") }
+			
+			if err = sshAction.scalableGetCPHA(cpha); err != nil {
+				return nil, err
 			}
+			
+			return cpha, nil
 		
 		case PlatformXBM:
 			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): PlatformXBM\n") }
@@ -84,29 +89,56 @@ func (sshAction *SshAction) GetCPHA() (cpha *CphaData, err error) {
 		return nil, New(4002, err.Error())
 	}
 
-	lines := strings.Split(result, "\n")
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): Status = %s, Mode = %s\n", cpha.Status, cpha.Mode) }
 	
-	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): lines = %q\n", lines) }
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): end\n") }
 	
-	// go thru each line
-	for _, v := range lines {
-		if strings.Contains(v, "not started") {
-			cpha.Status = "not_started"
-			
-			break
-		} else if strings.Contains(v, "(local)") {
-			f := strings.Fields(v)
-			n := len(f)
-			
-			cpha.Status = strings.ToLower(f[n - 1])
+	return cpha, nil
+}
 
-			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): Status = %s\n", cpha.Status) }
-			
-			break
-		}
+//
+// cphaCollect runs the cphaprob commands; only cphaprob stat is required, the
+// others are skipped when clustering is not started
+func (sshAction *SshAction) cphaCollect(cpha *CphaData, redirect string) (err error) {
+	var result string
+	
+	result, err = sshAction.execute("cphaprob stat" + redirect, 10)
+	if err != nil {
+		if sshAction.verbose > 0 { fmt.Printf("SshAction::cphaCollect(): unable to execute 'cphaprob stat%s'\n", redirect) }
+		return err
 	}
 	
-	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetCPHA(): end\n") }
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::cphaCollect(): lines = %q\n", strings.Split(result, "\n")) }
 	
-	return cpha, nil
-}
\ No newline at end of file
+	parsed, warnings := parser.ParseCphaprobStat(result)
+	
+	*cpha = *parsed
+	sshAction.warn(warnings)
+	
+	if cpha.Status == "not_started" || cpha.Status == "" {
+		return nil
+	}
+	
+	if result, err = sshAction.execute("cphaprob list" + redirect, 10); err == nil {
+		sshAction.warn(parser.ParseCphaprobList(cpha, result))
+	}
+	
+	if result, err = sshAction.execute("cphaprob -a if" + redirect, 10); err == nil {
+		sshAction.warn(parser.ParseCphaprobIf(cpha, result))
+	}
+	
+	// R80.20 and later; older releases fall back to the Synchronization pnote
+	if result, err = sshAction.execute("cphaprob syncstat" + redirect, 10); err == nil {
+		parser.ParseCphaprobSyncstat(cpha, result)
+	}
+	
+	if cpha.SyncStatus == "" {
+		for _, d := range cpha.Devices {
+			if d.Name == "Synchronization" {
+				cpha.SyncStatus = d.State
+			}
+		}
+	}
+	
+	return nil
+}
@@ -27,14 +27,12 @@ import (
 	"strconv"
 	"time"
     "regexp"
+	"github.com/mikejac/ssh.golang/parser"
 )
 
-type VAPGroup struct {
-	Name	string
-	Count	int
-}
-
-type VAPGroups []VAPGroup
+// The VAP group types are defined, and parsed, in the parser package
+type VAPGroup	= parser.VAPGroup
+type VAPGroups	= parser.VAPGroups
 
 //
 //
@@ -47,6 +45,12 @@ func (sshAction *SshAction) GetVAPGroups() (vapGroups VAPGroups, err error) {
 		case PlatformGAiA:
 			fallthrough
 			
+		case PlatformGaiaEmbedded:
+			fallthrough
+			
+		case PlatformScalable:
+			fallthrough
+			
 		case PlatformSplatCPSHELL:
 			fallthrough
 
@@ -71,48 +75,13 @@ func (sshAction *SshAction) GetVAPGroups() (vapGroups VAPGroups, err error) {
 			return vapGroups, New(5001, "platform unknown")
 	}
 				
-	lines := strings.Split(result, "\n")
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVAPGroups(): lines = %q\n", strings.Split(result, "\n")) }
 	
-	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVAPGroups(): lines = %q\n", lines) }
+	vapGroups, warnings := parser.ParseVAPGroups(result)
 	
-	var vapGroup	string
-	var vapCount	string
+	sshAction.warn(warnings)
 	
-	// go thru each line
-	for _, v := range lines {
-		if strings.HasPrefix(v, "VAP Group") {
-			f := strings.Split(v, ":")
-			n := len(f)
-			
-			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVAPGroups(): n = %d, f = %q\n", n, f) }
-			
-			if n == 2 {
-				vapGroup = strings.TrimSpace(f[1])
-			
-				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVAPGroups(): vapGroup = '%s'\n", vapGroup) }
-			}
-		} else if strings.HasPrefix(v, "VAP Count") {
-			f := strings.Split(v, ":")
-			n := len(f)
-			
-			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVAPGroups(): n = %d, f = %q\n", n, f) }
-			
-			if n == 2 {
-				vapCount = strings.TrimSpace(f[1])
-			
-				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVAPs(): vapCount = '%s'\n", vapCount) }
-				
-				//
-				// we have the info we want from this VAP so store it
-				//
-				var vap VAPGroup
-				vap.Name		= vapGroup
-				vap.Count, _	= strconv.Atoi(vapCount)
-				
-				vapGroups = append(vapGroups, vap)
-			}
-		}
-	}
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVAPGroups(): vapGroups = %v\n", vapGroups) }
 
 	return vapGroups, nil
 }
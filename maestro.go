@@ -0,0 +1,210 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"github.com/mikejac/ssh.golang/parser"
+)
+
+// SGMState is defined, and parsed, in the parser package
+type SGMState = parser.SGMState
+
+//
+// ConnectSGM opens an expert shell on a single Security Group Member. Until
+// DisconnectSGM is called, collectors run on that member only.
+func (sshAction *SshAction) ConnectSGM(site int, member int) (err error) {
+	if sshAction.verbose > 0 { fmt.Println("SshAction::ConnectSGM(): start") }
+
+	if sshAction.platform != PlatformScalable {
+		return New(7000, "not platform scalable")
+	}
+
+	if err = sshAction.expertEnter(); err != nil {
+		return New(7001, err.Error())
+	}
+
+	id := parser.SGMID(site, member)
+
+	// a member that cannot be reached leaves us at this very prompt
+	previous := sshAction.currentPrompt
+
+	done := make(chan error, 1)
+
+	go func(done chan error) {
+		buf := make([]byte, inBufferSize)
+		t   := 0
+
+		for {
+			n, err := sshAction.out.Read(buf[t:])
+			if err != nil {
+				sshAction.prompt = -1
+
+				if sshAction.verbose > 0 { fmt.Printf("SshAction::ConnectSGM(): failed to read: %s\n", err.Error()) }
+
+				// signal parent we're done
+				done <- New(7002, err.Error())
+				break
+			}
+
+			if n > 0 {
+				str := string(buf[:])
+				str  = strings.TrimRight(str[0:len(str)], "\000")
+
+				t += n
+				if sshAction.verbose > 0 { fmt.Println(str) }
+
+				// the member's prompt carries its own host name, e.g. '[Expert@gw-ch01-02:0]# '
+				if sshAction.prompt2.MatchString(str) {
+					sshAction.prompt = 2
+					if sshAction.verbose > 0 { fmt.Printf("SshAction::ConnectSGM(): found prompt 2\n") }
+
+					// signal parent we're done
+					done <- sshAction.findPrompt(string(buf[:]))
+					break
+				}
+			}
+		}
+	}(done)
+
+	sshAction.in.Write([]byte("member " + id + "\n"))
+
+	/******************************************************************************************************************
+	 * wait for transaction to complete or timeout
+	 *
+	 */
+	select {
+		case err = <-done:
+			if sshAction.verbose > 0 { fmt.Println("SshAction::ConnectSGM(): completed") }
+			break
+		case <- time.After(time.Duration(promptXBMWaitTimeout) * time.Second):
+			if sshAction.verbose > 0 { fmt.Println("SshAction::ConnectSGM(): timeout") }
+			err = New(7003, "timeout")
+			break
+	}
+
+	if err == nil && sshAction.currentPrompt == previous {
+		if sshAction.verbose > 0 { fmt.Printf("SshAction::ConnectSGM(): still at prompt '%s'\n", previous) }
+
+		err = New(7004, "failed to reach member " + id)
+	}
+
+	if err != nil {
+		sshAction.expertExit()
+		return err
+	}
+
+	sshAction.platform = PlatformExpert
+
+	return nil
+}
+
+//
+//
+func (sshAction *SshAction) DisconnectSGM() (err error) {
+	if sshAction.verbose > 0 { fmt.Println("SshAction::DisconnectSGM(): start") }
+
+	sshAction.in.Write([]byte("exit\n"))								// exit from SGM
+
+	if err = sshAction.waitfor(); err != nil {
+		return New(7010, "failed to locate prompt")
+	}
+
+	if err = sshAction.expertExit(); err != nil {						// exit from expert
+		return err
+	}
+
+	sshAction.platform = PlatformScalable
+
+	return nil
+}
+
+//
+// ExecuteAllSGMs runs cmd on every member with g_all and returns each member's
+// output keyed by SGM ID
+func (sshAction *SshAction) ExecuteAllSGMs(cmd string, timeout int) (results map[string]string, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::ExecuteAllSGMs(): begin\n") }
+
+	var result string
+
+	switch sshAction.platform {
+		case PlatformScalable:
+			if err = sshAction.expertEnter(); err != nil {
+				return nil, New(7020, err.Error())
+			}
+
+			result, err = sshAction.execute("g_all " + cmd + " 2>&1", timeout)
+
+			sshAction.expertExit()
+
+		default:
+			return nil, New(7021, "not platform scalable")
+	}
+
+	if err != nil {
+		return nil, New(7022, err.Error())
+	}
+
+	results, warnings := parser.ParseGAll(result)
+
+	sshAction.warn(warnings)
+
+	return results, nil
+}
+
+//
+//
+func (sshAction *SshAction) scalableGetCPHA(cpha *CphaData) (err error) {
+	var result		string
+	var warnings	parser.Warnings
+
+	if err = sshAction.expertEnter(); err != nil {
+		return New(4010, err.Error())
+	}
+
+	result, err = sshAction.execute("asg monitor 2>&1", 20)
+	if err != nil {
+		if sshAction.verbose > 0 { fmt.Printf("SshAction::scalableGetCPHA(): unable to execute 'asg monitor 2>&1'\n") }
+	}
+
+	sshAction.expertExit()
+
+	if err != nil {
+		return New(4011, err.Error())
+	}
+
+	cpha.SGMs, warnings = parser.ParseAsgMonitor(result)
+	
+	sshAction.warn(warnings)
+
+	for _, sgm := range cpha.SGMs {
+		if sgm.Local {
+			cpha.Status = sgm.State
+		}
+
+		if sshAction.verbose >= 1 { fmt.Printf("SshAction::scalableGetCPHA(): sgm = %s, state = %s, local = %v\n", sgm.ID, sgm.State, sgm.Local) }
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+
+package sshtool
+
+import (
+	"testing"
+)
+
+//
+//
+func TestConnectSGM(t *testing.T) {
+	for _, tt := range []struct {
+		member		string				// output of 'member 1_02'
+		err			int					// error number, 0 for none
+		platform	Platform
+	}{
+		{"Moving to member 1_02\n[Expert@gw-ch01-02:0]# ", 0, PlatformExpert},
+		// a member that is down: the SMO prompt comes back
+		{"Member 1_02 is not reachable\n[Expert@gw-ch01-01:0]# ", 7004, PlatformScalable},
+	} {
+		sshAction := newPromptSession("", "", map[string]string{
+			"expert":		"[Expert@gw-ch01-01:0]# ",
+			"member 1_02":	tt.member,
+			"exit":			"[Global] gw-ch01-01 > ",
+		})
+		sshAction.platform = PlatformScalable
+
+		got := 0
+
+		if err := sshAction.ConnectSGM(1, 2); err != nil {
+			got = errorNumber(err)
+		}
+
+		if got != tt.err {
+			t.Errorf("%q: error %d, want %d", tt.member, got, tt.err)
+		}
+
+		if sshAction.platform != tt.platform {
+			t.Errorf("%q: platform = %d, want %d", tt.member, sshAction.platform, tt.platform)
+		}
+
+		sshAction.in.Close()
+	}
+}
@@ -0,0 +1,291 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"fmt"
+	"strings"
+	"github.com/mikejac/ssh.golang/parser"
+)
+
+// The management types are defined, and parsed, in the parser package
+type ProcessState	= parser.ProcessState
+type ManagementInfo	= parser.ManagementInfo
+type Domain			= parser.Domain
+type Domains		= parser.Domains
+
+// DomainResult is what a collector returned when run inside one domain
+type DomainResult struct {
+	Domain		Domain
+	Result		interface{}
+	Err			error
+}
+
+// DomainResults holds collector results keyed by domain name
+type DomainResults map[string]*DomainResult
+
+//
+//
+func (sshAction *SshAction) GetManagementInfo() (info *ManagementInfo, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetManagementInfo(): begin\n") }
+
+	info = &ManagementInfo{}
+
+	switch sshAction.platform {
+		case PlatformGAiA:
+			fallthrough
+
+		case PlatformSplatCPSHELL:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetManagementInfo(): PlatformGAiA or PlatformSplatCPSHELL\n") }
+
+			if err = sshAction.expertEnter(); err == nil {
+				err = sshAction.expertGetManagementInfo(info)
+
+				sshAction.expertExit()
+			}
+
+		case PlatformExpert:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetManagementInfo(): PlatformExpert\n") }
+
+			err = sshAction.expertGetManagementInfo(info)
+
+		case PlatformGaiaEmbedded:
+			fallthrough
+
+		case PlatformScalable:
+			fallthrough
+
+		case PlatformIPSO:
+			fallthrough
+
+		case PlatformXBM:
+			// gateway-only platforms
+			info.IsGateway = true
+
+			return info, nil
+
+		default:
+			fmt.Printf("SshAction::GetManagementInfo(): unknown platform\n")
+			return nil, New(8001, "platform unknown")
+	}
+
+	if err != nil {
+		return nil, New(8002, err.Error())
+	}
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetManagementInfo(): end\n") }
+
+	return info, nil
+}
+
+//
+//
+func (sshAction *SshAction) expertGetManagementInfo(info *ManagementInfo) (err error) {
+	var result string
+
+	if result, err = sshAction.execute("cpprod_util FwIsFirewallModule 2>&1", 10); err != nil {
+		return err
+	}
+
+	info.IsGateway = strings.TrimSpace(result) == "1"
+
+	if result, err = sshAction.execute("cpprod_util FwIsFirewallMgmt 2>&1", 10); err != nil {
+		return err
+	}
+
+	info.IsManagement = strings.TrimSpace(result) == "1"
+
+	// MDSDIR is only set in the environment of a Multi-Domain Server
+	if result, err = sshAction.execute("echo \"MDSDIR=$MDSDIR\"", 5); err != nil {
+		return err
+	}
+
+	info.IsMDS = strings.TrimSpace(result) != "MDSDIR="
+
+	if sshAction.verbose >= 1 { fmt.Printf("SshAction::expertGetManagementInfo(): gateway = %v, management = %v, mds = %v\n", info.IsGateway, info.IsManagement, info.IsMDS) }
+
+	if !info.IsManagement && !info.IsMDS {
+		return nil
+	}
+
+	if result, err = sshAction.execute("cpstat mg 2>&1", 20); err != nil {
+		return err
+	}
+
+	parser.ParseCpstatMg(info, result)
+
+	if result, err = sshAction.execute("cpwd_admin list 2>&1", 10); err != nil {
+		return err
+	}
+
+	var warnings parser.Warnings
+
+	info.Processes, warnings = parser.ParseCpwdAdminList(result)
+
+	sshAction.warn(warnings)
+
+	if result, err = sshAction.execute("cat $FWDIR/tmp/manage.lock 2>/dev/null", 5); err != nil {
+		return err
+	}
+
+	info.LockOwner = strings.TrimSpace(result)
+
+	return nil
+}
+
+//
+// GetDomains lists the MDS and its domain management servers from 'mdsstat'
+func (sshAction *SshAction) GetDomains() (domains Domains, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetDomains(): begin\n") }
+
+	var result string
+
+	switch sshAction.platform {
+		case PlatformGAiA:
+			fallthrough
+
+		case PlatformSplatCPSHELL:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetDomains(): PlatformGAiA or PlatformSplatCPSHELL\n") }
+
+			if sshAction.expertEnter() == nil {
+				result, err = sshAction.execute("mdsstat 2>&1", 60)
+				if err != nil {
+					if sshAction.verbose > 0 { fmt.Printf("SshAction::GetDomains(): unable to execute 'mdsstat 2>&1'\n") }
+				}
+
+				sshAction.expertExit()
+			}
+
+		case PlatformExpert:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetDomains(): PlatformExpert\n") }
+
+			result, err = sshAction.execute("mdsstat 2>&1", 60)
+			if err != nil {
+				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetDomains(): unable to execute 'mdsstat 2>&1'\n") }
+			}
+
+		default:
+			return nil, New(8100, "platform does not support mds")
+	}
+
+	if err != nil {
+		return nil, New(8101, err.Error())
+	}
+
+	domains, warnings := parser.ParseMdsstat(result)
+
+	sshAction.warn(warnings)
+
+	if len(domains) == 0 {
+		return nil, New(8102, "not a multi-domain server")
+	}
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetDomains(): end\n") }
+
+	return domains, nil
+}
+
+//
+// SwitchDomain runs mdsenv so that following commands act on domain name; an
+// empty name returns to the MDS level. Only valid while connected in Expert-mode;
+// see ForEachDomain otherwise.
+func (sshAction *SshAction) SwitchDomain(name string) (err error) {
+	if sshAction.platform != PlatformExpert {
+		return New(8200, "mdsenv requires expert-mode")
+	}
+
+	return sshAction.mdsenv(name)
+}
+
+//
+// ForEachDomain runs collect inside the mdsenv context of every CMA in domains
+// and returns to the MDS level afterwards
+func (sshAction *SshAction) ForEachDomain(domains Domains, collect func(*SshAction) (interface{}, error)) (results DomainResults, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::ForEachDomain(): begin\n") }
+
+	platform := sshAction.platform
+
+	switch platform {
+		case PlatformGAiA:
+			fallthrough
+
+		case PlatformSplatCPSHELL:
+			if err = sshAction.expertEnter(); err != nil {
+				return nil, New(8210, err.Error())
+			}
+
+			sshAction.platform = PlatformExpert
+
+		case PlatformExpert:
+
+		default:
+			return nil, New(8211, "platform does not support mds")
+	}
+
+	results = make(DomainResults)
+
+	for _, d := range domains {
+		if d.Type != "CMA" {
+			continue
+		}
+
+		r := &DomainResult{Domain: d}
+
+		if r.Err = sshAction.mdsenv(d.Name); r.Err == nil {
+			r.Result, r.Err = collect(sshAction)
+		}
+
+		if r.Err != nil {
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::ForEachDomain(): %s: %s\n", d.Name, r.Err.Error()) }
+		}
+
+		results[d.Name] = r
+	}
+
+	err = sshAction.mdsenv("")
+
+	if sshAction.platform != platform {
+		sshAction.platform = platform
+		sshAction.expertExit()
+	}
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::ForEachDomain(): end\n") }
+
+	return results, err
+}
+
+//
+// mdsenv prints nothing on success; anything else is its error message
+func (sshAction *SshAction) mdsenv(name string) (error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::mdsenv(): name = '%s'\n", name) }
+
+	result, err := sshAction.execute(strings.TrimSpace("mdsenv " + name) + " 2>&1", 20)
+	if err != nil {
+		return New(8220, err.Error())
+	}
+
+	if result = strings.TrimSpace(result); result != "" {
+		return New(8221, result)
+	}
+
+	return nil
+}
@@ -0,0 +1,342 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"encoding/json"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MgmtArg is one 'key value' pair of a management API command. Nested
+// parameters use mgmt_cli's dotted notation, e.g. "interfaces.1.name".
+type MgmtArg struct {
+	Key			string
+	Value		string
+}
+
+// MgmtCommand is one management API call, e.g. "add-network"
+type MgmtCommand struct {
+	Command		string
+	Args		[]MgmtArg
+}
+
+type MgmtCommands []MgmtCommand
+
+//
+// ExportMgmtObjects turns the discovered interfaces, routes and topology of
+// gateway into management objects: a network per connected or routed
+// network, a host per next hop, a group per interface topology and finally
+// the interface definitions of the simple-gateway object named gateway
+func ExportMgmtObjects(gateway string, logical LogicalInterfaces, routes Routes, topology Topology) (cmds MgmtCommands) {
+	seen := make(map[string]bool)
+
+	addNetwork := func(n net.IPNet) (string) {
+		name := mgmtNetworkName(n)
+
+		if seen[name] {
+			return name
+		}
+
+		seen[name] = true
+
+		ones, _ := n.Mask.Size()
+
+		if n.IP.To4() != nil {
+			cmds = append(cmds, MgmtCommand{"add-network", []MgmtArg{{"name", name}, {"subnet4", n.IP.String()}, {"mask-length4", strconv.Itoa(ones)}}})
+		} else {
+			cmds = append(cmds, MgmtCommand{"add-network", []MgmtArg{{"name", name}, {"subnet6", n.IP.String()}, {"mask-length6", strconv.Itoa(ones)}}})
+		}
+
+		return name
+	}
+
+	addHost := func(ip net.IP) {
+		name := "host_" + mgmtAddrName(ip)
+
+		if seen[name] {
+			return
+		}
+
+		seen[name] = true
+
+		if ip.To4() != nil {
+			cmds = append(cmds, MgmtCommand{"add-host", []MgmtArg{{"name", name}, {"ipv4-address", ip.String()}}})
+		} else {
+			cmds = append(cmds, MgmtCommand{"add-host", []MgmtArg{{"name", name}, {"ipv6-address", ip.String()}}})
+		}
+	}
+
+	// IPSO gives connected routes the interface's own address as gateway
+	own := make(map[string]bool)
+
+	for _, i := range logical {
+		if i.Addr != nil && i.Mask != nil {
+			addNetwork(net.IPNet{IP: i.Addr.Mask(i.Mask), Mask: i.Mask})
+		}
+
+		if i.Addr != nil {
+			own[i.Addr.String()] = true
+		}
+	}
+
+	for _, r := range routes {
+		if ones, _ := r.IPNet.Mask.Size(); r.IPNet.IP != nil && ones > 0 {
+			addNetwork(r.IPNet)
+		}
+
+		// link-local next hops mean nothing outside their segment
+		if gw := net.ParseIP(r.Gateway); gw != nil && !gw.IsLinkLocalUnicast() && !own[gw.String()] {
+			addHost(gw)
+		}
+	}
+
+	groups := make(map[string]string)
+
+	for _, t := range topology {
+		// external interfaces get no specific topology
+		if len(t.Summary) == 0 || t.External {
+			continue
+		}
+
+		group := "grp_" + gateway + "_" + t.IfName
+		args  := []MgmtArg{{"name", group}}
+
+		for n, s := range t.Summary {
+			args = append(args, MgmtArg{"members." + strconv.Itoa(n + 1), addNetwork(s)})
+		}
+
+		cmds = append(cmds, MgmtCommand{"add-group", args})
+
+		groups[t.IfName] = group
+	}
+
+	cmds = append(cmds, mgmtGatewayInterfaces(gateway, logical, topology, groups))
+
+	return cmds
+}
+
+//
+// MgmtCLI renders cmds as mgmt_cli command lines, one per command, ready for
+// a shell script run after 'mgmt_cli login > id.txt'
+func (cmds MgmtCommands) MgmtCLI() (string) {
+	var lines []string
+
+	for _, c := range cmds {
+		line := "mgmt_cli " + strings.Replace(c.Command, "-", " ", 1)
+
+		for _, a := range c.Args {
+			line += " " + a.Key + " " + strconv.Quote(a.Value)
+		}
+
+		lines = append(lines, line + " -s id.txt")
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+//
+// JSON renders cmds as a list of {"command": ..., "payload": {...}} with the
+// dotted keys expanded into objects and arrays as the web API expects them
+func (cmds MgmtCommands) JSON() ([]byte, error) {
+	type call struct {
+		Command		string					`json:"command"`
+		Payload		interface{}				`json:"payload"`
+	}
+
+	var calls []call
+
+	for _, c := range cmds {
+		payload := make(map[string]interface{})
+
+		for _, a := range c.Args {
+			keys := strings.Split(a.Key, ".")
+
+			mgmtSet(payload, keys, mgmtValue(keys[len(keys) - 1], a.Value))
+		}
+
+		calls = append(calls, call{c.Command, mgmtArrays(payload)})
+	}
+
+	return json.MarshalIndent(calls, "", "  ")
+}
+
+/******************************************************************************************************************
+* helper functions
+*
+*/
+
+//
+//
+func mgmtGatewayInterfaces(gateway string, logical LogicalInterfaces, topology Topology, groups map[string]string) (MgmtCommand) {
+	args := []MgmtArg{{"name", gateway}}
+
+	external := make(map[string]bool)
+
+	for _, t := range topology {
+		external[t.IfName] = t.External
+	}
+
+	// one definition per interface with its first address of each family
+	var names []string
+	v4 := make(map[string]NetworkLogicalInterface)
+	v6 := make(map[string]NetworkLogicalInterface)
+
+	for _, i := range logical {
+		if i.Addr == nil || i.Mask == nil {
+			continue
+		}
+
+		name := topologyName(i.IfName)
+
+		_, has4 := v4[name]
+		_, has6 := v6[name]
+
+		if !has4 && !has6 {
+			names = append(names, name)
+		}
+
+		if i.Addr.To4() != nil && !has4 {
+			v4[name] = i
+		} else if i.Addr.To4() == nil && !has6 {
+			v6[name] = i
+		}
+	}
+
+	sort.Strings(names)
+
+	for n, name := range names {
+		p := "interfaces." + strconv.Itoa(n + 1) + "."
+
+		args = append(args, MgmtArg{p + "name", name})
+
+		if i, ok := v4[name]; ok {
+			ones, _ := i.Mask.Size()
+			args = append(args, MgmtArg{p + "ipv4-address", i.Addr.String()}, MgmtArg{p + "ipv4-mask-length", strconv.Itoa(ones)})
+		}
+
+		if i, ok := v6[name]; ok {
+			ones, _ := i.Mask.Size()
+			args = append(args, MgmtArg{p + "ipv6-address", i.Addr.String()}, MgmtArg{p + "ipv6-mask-length", strconv.Itoa(ones)})
+		}
+
+		if external[name] {
+			args = append(args, MgmtArg{p + "topology", "external"})
+		} else {
+			args = append(args, MgmtArg{p + "topology", "internal"})
+
+			if group, ok := groups[name]; ok {
+				args = append(args,
+					MgmtArg{p + "topology-settings.ip-address-behind-this-interface", "specific"},
+					MgmtArg{p + "topology-settings.specific-network", group})
+			} else {
+				args = append(args, MgmtArg{p + "topology-settings.ip-address-behind-this-interface", "network defined by the interface ip and net mask"})
+			}
+		}
+
+		args = append(args, MgmtArg{p + "anti-spoofing", "true"})
+	}
+
+	return MgmtCommand{"set-simple-gateway", args}
+}
+
+//
+// mgmtNetworkName gives e.g. "net_10.1.0.0_16"
+func mgmtNetworkName(n net.IPNet) (string) {
+	ones, _ := n.Mask.Size()
+
+	return "net_" + mgmtAddrName(n.IP) + "_" + strconv.Itoa(ones)
+}
+
+//
+// mgmtAddrName keeps the address readable; IPv6 colons are not welcome in
+// object names
+func mgmtAddrName(ip net.IP) (string) {
+	return strings.Replace(ip.String(), ":", "-", -1)
+}
+
+// mgmtBools are the keys the web API wants as JSON booleans; mgmt_cli takes
+// them as "true" or "false" like any other value
+var mgmtBools = map[string]bool{
+	"anti-spoofing":	true,
+}
+
+//
+// mgmtValue gives the JSON value of key
+func mgmtValue(key string, value string) (interface{}) {
+	if mgmtBools[key] {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+
+	return value
+}
+
+//
+//
+func mgmtSet(m map[string]interface{}, keys []string, value interface{}) {
+	if len(keys) == 1 {
+		m[keys[0]] = value
+		return
+	}
+
+	sub, ok := m[keys[0]].(map[string]interface{})
+	if !ok {
+		sub = make(map[string]interface{})
+		m[keys[0]] = sub
+	}
+
+	mgmtSet(sub, keys[1:], value)
+}
+
+//
+// mgmtArrays turns objects whose keys are all 1, 2, ... into arrays
+func mgmtArrays(v interface{}) (interface{}) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+
+	for k := range m {
+		m[k] = mgmtArrays(m[k])
+	}
+
+	list := make([]interface{}, len(m))
+
+	for k, e := range m {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 1 || i > len(m) {
+			return m
+		}
+
+		list[i - 1] = e
+	}
+
+	if len(list) == 0 {
+		return m
+	}
+
+	return list
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+
+package sshtool
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+)
+
+//
+//
+func TestMgmtCommandsJSON(t *testing.T) {
+	_, ipnet, _ := net.ParseCIDR("10.0.0.0/24")
+
+	logical := LogicalInterfaces{{IfName: "eth1", IfIP: "10.0.0.1", Addr: net.ParseIP("10.0.0.1"), Mask: ipnet.Mask, Family: 4}}
+	cmds    := ExportMgmtObjects("gw-test", logical, nil, nil)
+
+	data, err := cmds.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls []struct {
+		Command		string
+		Payload		struct {
+			Name		string
+			Interfaces	[]map[string]interface{}
+		}
+	}
+
+	if err := json.Unmarshal(data, &calls); err != nil {
+		t.Fatal(err)
+	}
+
+	gw := calls[len(calls) - 1]
+
+	if gw.Command != "set-simple-gateway" || gw.Payload.Name != "gw-test" || len(gw.Payload.Interfaces) != 1 {
+		t.Fatalf("last call = %+v", gw)
+	}
+
+	if v, ok := gw.Payload.Interfaces[0]["anti-spoofing"].(bool); !ok || !v {
+		t.Errorf("anti-spoofing = %#v, want true", gw.Payload.Interfaces[0]["anti-spoofing"])
+	}
+
+	if v := gw.Payload.Interfaces[0]["name"]; v != "eth1" {
+		t.Errorf("name = %#v, want \"eth1\"", v)
+	}
+
+	// mgmt_cli takes every value as a string
+	if cli := cmds.MgmtCLI(); !strings.Contains(cli, ` interfaces.1.anti-spoofing "true"`) {
+		t.Errorf("MgmtCLI() = %s", cli)
+	}
+}
+
+//
+//
+func TestExportMgmtObjects(t *testing.T) {
+	logical := LogicalInterfaces{
+		testLogical("eth-s1p1c0", "10.0.0.1/24"),
+		testLogical("eth-s1p2c0", "172.16.100.1/24"),
+		testLogical("eth-s1p3c0", "192.168.1.1/24"),
+		testLogical("eth-s1p4c0", "10.9.9.1/24"),
+	}
+
+	// as IPSO netstat -rn gives them: connected routes through our own address
+	routes := Routes{
+		testRoute("0.0.0.0/0", "192.168.1.254", "eth-s1p3c0", 0),
+		testRoute("10.0.0.0/24", "10.0.0.1", "eth-s1p1c0", 0),
+		testRoute("10.20.0.0/16", "10.0.0.2", "eth-s1p1c0", 0),
+		testRoute("10.21.0.0/16", "10.0.0.2", "eth-s1p1c0", 0),
+		testRoute("172.16.100.0/24", "172.16.100.1", "eth-s1p2c0", 0),
+		testRoute("192.168.1.0/24", "192.168.1.1", "eth-s1p3c0", 0),
+	}
+
+	// leave eth-s1p4c0 out of the topology: it keeps the network of its address
+	topology := GetTopology(logical, routes)[:3]
+
+	want := `mgmt_cli add network name "net_10.0.0.0_24" subnet4 "10.0.0.0" mask-length4 "24" -s id.txt
+mgmt_cli add network name "net_172.16.100.0_24" subnet4 "172.16.100.0" mask-length4 "24" -s id.txt
+mgmt_cli add network name "net_192.168.1.0_24" subnet4 "192.168.1.0" mask-length4 "24" -s id.txt
+mgmt_cli add network name "net_10.9.9.0_24" subnet4 "10.9.9.0" mask-length4 "24" -s id.txt
+mgmt_cli add host name "host_192.168.1.254" ipv4-address "192.168.1.254" -s id.txt
+mgmt_cli add network name "net_10.20.0.0_16" subnet4 "10.20.0.0" mask-length4 "16" -s id.txt
+mgmt_cli add host name "host_10.0.0.2" ipv4-address "10.0.0.2" -s id.txt
+mgmt_cli add network name "net_10.21.0.0_16" subnet4 "10.21.0.0" mask-length4 "16" -s id.txt
+mgmt_cli add network name "net_10.20.0.0_15" subnet4 "10.20.0.0" mask-length4 "15" -s id.txt
+mgmt_cli add group name "grp_gw-test_eth-s1p1c0" members.1 "net_10.0.0.0_24" members.2 "net_10.20.0.0_15" -s id.txt
+mgmt_cli add group name "grp_gw-test_eth-s1p2c0" members.1 "net_172.16.100.0_24" -s id.txt
+mgmt_cli set simple-gateway name "gw-test"` +
+		` interfaces.1.name "eth-s1p1c0" interfaces.1.ipv4-address "10.0.0.1" interfaces.1.ipv4-mask-length "24" interfaces.1.topology "internal"` +
+		` interfaces.1.topology-settings.ip-address-behind-this-interface "specific" interfaces.1.topology-settings.specific-network "grp_gw-test_eth-s1p1c0" interfaces.1.anti-spoofing "true"` +
+		` interfaces.2.name "eth-s1p2c0" interfaces.2.ipv4-address "172.16.100.1" interfaces.2.ipv4-mask-length "24" interfaces.2.topology "internal"` +
+		` interfaces.2.topology-settings.ip-address-behind-this-interface "specific" interfaces.2.topology-settings.specific-network "grp_gw-test_eth-s1p2c0" interfaces.2.anti-spoofing "true"` +
+		` interfaces.3.name "eth-s1p3c0" interfaces.3.ipv4-address "192.168.1.1" interfaces.3.ipv4-mask-length "24" interfaces.3.topology "external" interfaces.3.anti-spoofing "true"` +
+		` interfaces.4.name "eth-s1p4c0" interfaces.4.ipv4-address "10.9.9.1" interfaces.4.ipv4-mask-length "24" interfaces.4.topology "internal"` +
+		` interfaces.4.topology-settings.ip-address-behind-this-interface "network defined by the interface ip and net mask" interfaces.4.anti-spoofing "true" -s id.txt
+`
+
+	if cli := ExportMgmtObjects("gw-test", logical, routes, topology).MgmtCLI(); cli != want {
+		t.Errorf("MgmtCLI() =\n%s\nwant\n%s", cli, want)
+	}
+}
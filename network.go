@@ -26,39 +26,16 @@ import (
 	"strings"
 	"strconv"
 	"sort"
-	"net"
+	"github.com/mikejac/ssh.golang/parser"
 )
 
-//
-//
-type NetworkLogicalInterface struct {
-	IfName	string
-	IfIP	string
-	Addr	net.IP
-	Mask	net.IPMask
-}
-
-type LogicalInterfaces []NetworkLogicalInterface
-
-//
-//
-type NetworkPhysicalInterface struct {
-	IfName	string
-	VLAN	string
-}
-
-type PhysicalInterfaces []NetworkPhysicalInterface
-
-//
-//
-type NetworkRoute struct {
-	Net			string
-	Gateway	string
-	Dev			string
-	IPNet		net.IPNet
-}
-
-type Routes []NetworkRoute
+// The network types are defined, and parsed, in the parser package
+type NetworkLogicalInterface	= parser.NetworkLogicalInterface
+type LogicalInterfaces			= parser.LogicalInterfaces
+type NetworkPhysicalInterface	= parser.NetworkPhysicalInterface
+type PhysicalInterfaces			= parser.PhysicalInterfaces
+type NetworkRoute				= parser.NetworkRoute
+type Routes						= parser.Routes
 
 //
 //
@@ -71,13 +48,19 @@ func (sshAction *SshAction) GetInterfaces() (logical LogicalInterfaces, err erro
 		case PlatformGAiA:
 			fallthrough
 			
+		case PlatformGaiaEmbedded:
+			fallthrough
+			
+		case PlatformScalable:
+			fallthrough
+			
 		case PlatformSplatCPSHELL:
-			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInterfaces(): PlatformGAiA or PlatformSplatCPSHELL\n") }
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInterfaces(): PlatformGAiA, PlatformGaiaEmbedded, PlatformScalable or PlatformSplatCPSHELL\n") }
 					
 			if sshAction.expertEnter() == nil {
-				result, err = sshAction.execute("ip -o -f inet addr 2>&1", 10)
+				result, err = sshAction.execute("ip -o -f inet addr 2>&1; ip -o -f inet6 addr 2>&1", 10)
 				if err != nil {
-					if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInterfaces(): unable to execute 'ip -o -f inet addr 2>&1'\n") }
+					if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInterfaces(): unable to execute 'ip -o -f inet[6] addr 2>&1'\n") }
 				}
 			
 				sshAction.expertExit()
@@ -86,9 +69,9 @@ func (sshAction *SshAction) GetInterfaces() (logical LogicalInterfaces, err erro
 		case PlatformExpert:
 			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInterfaces(): PlatformExpert\n") }
 
-			result, err = sshAction.execute("ip -o -f inet addr 2>&1", 10)
+			result, err = sshAction.execute("ip -o -f inet addr 2>&1; ip -o -f inet6 addr 2>&1", 10)
 			if err != nil {
-				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInterfaces(): unable to execute 'ip -o -f inet addr 2>&1'\n") }
+				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInterfaces(): unable to execute 'ip -o -f inet[6] addr 2>&1'\n") }
 			}
 		
 		case PlatformIPSO:
@@ -98,9 +81,11 @@ func (sshAction *SshAction) GetInterfaces() (logical LogicalInterfaces, err erro
 			if err != nil {
 				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInterfaces(): unable to execute 'ifconfig -a'\n") }
 			} else {
-				var physical PhysicalInterfaces
+				var warnings parser.Warnings
 				
-				err = sshAction.ipsoInterfaces(result, &logical, &physical)
+				logical, _, warnings = parser.ParseIPSOIfconfig(result)
+				
+				sshAction.warn(warnings)
 			}
 
 			sort.Sort(logical)
@@ -122,46 +107,15 @@ func (sshAction *SshAction) GetInterfaces() (logical LogicalInterfaces, err erro
 		return nil, New(3002, err.Error())
 	}
 	
-	lines := strings.Split(result, "\n")
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInterfaces(): lines = %q\n", strings.Split(result, "\n")) }
 	
-	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInterfaces(): lines = %q\n", lines) }
+	logical, warnings := parser.ParseIPAddr(result)
 	
-	// go thru each line
-	for _, v := range lines {
-		f := strings.Fields(v)
-		n := len(f)
-
-		if n > 2 {
-			if !strings.HasPrefix(f[1], "lo") {
-				if n > 4 {
-					a  := strings.Split(f[3], "/")
-					
-					addr := net.ParseIP(a[0])
-   					if addr == nil {
-						fmt.Printf("SshAction::GetInterfaces(): invalid address '%s'\n", v)				
-   					} else {
-						var ni NetworkLogicalInterface
-						ni.IfName = f[1]
-						ni.IfIP   = f[3]
-						ni.Addr   = addr
-
-						if len(a) == 2 {
-							m, _    := strconv.Atoi(a[1])
-							ni.Mask  = net.CIDRMask(m, 32)
-						} else {
-							ni.Mask  = net.CIDRMask(32, 32)
-						}
-						
-						logical = append(logical, ni)
-												
-						if sshAction.verbose >= 1 { fmt.Printf("SshAction::GetInterfaces(): ifname = '%s', ip = '%s'\n", ni.IfName, ni.IfIP) }
-					}
-				} else {
-					fmt.Printf("SshAction::GetInterfaces(): invalid address '%s'\n", v)
-				}
-			}
-		} else {
-			fmt.Printf("SshAction::GetInterfaces(): invalid address '%s'\n", v)
+	sshAction.warn(warnings)
+	
+	if sshAction.verbose >= 1 {
+		for _, ni := range logical {
+			fmt.Printf("SshAction::GetInterfaces(): ifname = '%s', ip = '%s'\n", ni.IfName, ni.IfIP)
 		}
 	}
 	
@@ -175,33 +129,68 @@ func (sshAction *SshAction) GetInterfaces() (logical LogicalInterfaces, err erro
 //
 // 3100
 func (sshAction *SshAction) GetPhyInterfaces(logical LogicalInterfaces) (physical PhysicalInterfaces, err error) {
+	vlans := sshAction.vlanTable(logical)
+	seen  := make(map[string]bool)
+	
 	for _, i := range logical {
-		p := strings.Split(i.IfName, ".")
+		// aliases, e.g. 'eth1:1', share the interface they are defined on
+		name := strings.SplitN(i.IfName, ":", 2)[0]
+		
+		if seen[name] {
+			continue
+		}
+		
+		seen[name] = true
 		
-		if sshAction.verbose >= 1 { fmt.Printf("SshAction::GetPhyInterfaces(): ifname = '%s', ip = '%s', len(p) = %d\n", i.IfName, i.IfIP, len(p)) }
-
 		var ni NetworkPhysicalInterface
 		
-		if len(p) == 1 {
-			ni.IfName = p[0]
-			physical = append(physical, ni)
-		} else if len(p) == 2 {
-			ni.IfName = p[0]
-			ni.VLAN   = p[1]
-			physical = append(physical, ni)
-		} else {
-			fmt.Printf("SshAction::GetPhyInterfaces(): invalid interface '%s'\n", i.IfName)						
-		}
+		resolveVLAN(&ni, name, vlans)
+		
+		if sshAction.verbose >= 1 { fmt.Printf("SshAction::GetPhyInterfaces(): ifname = '%s', ip = '%s', device = '%s', vlan = '%s', outer = '%s'\n", i.IfName, i.IfIP, ni.IfName, ni.VLAN, ni.OuterVLAN) }
+		
+		physical = append(physical, ni)
 	}
 
 	sort.Sort(physical)
 
-	if sshAction.verbose >= 1 { fmt.Printf("SshAction::GetPhyInterfaces(): physical = '%q'\n", physical) }
+	if sshAction.verbose >= 1 { fmt.Printf("SshAction::GetPhyInterfaces(): physical = '%v'\n", physical) }
 
 	return physical, nil
 
 }
 
+//
+// vlanTable returns the VLANs read from the system or, where it has no VLAN
+// table, those implied by the names of logical
+func (sshAction *SshAction) vlanTable(logical LogicalInterfaces) (vlans VLANs) {
+	vlans, err := sshAction.GetVLANs()
+	if err != nil {
+		if sshAction.verbose >= 1 { fmt.Printf("SshAction::vlanTable(): no vlan table (%s), using interface names\n", err.Error()) }
+		
+		vlans = parser.VLANsFromNames(logical)
+	}
+	
+	return vlans
+}
+
+//
+// resolveVLAN sets device, parent and tags of ni for the interface name
+func resolveVLAN(ni *NetworkPhysicalInterface, name string, vlans VLANs) {
+	device, vid, outer, ok := vlans.Resolve(name)
+	if !ok {
+		ni.IfName = name
+		return
+	}
+	
+	ni.IfName = device
+	ni.Parent = vlans[name].Parent
+	ni.VLAN   = strconv.Itoa(vid)
+	
+	if outer != 0 {
+		ni.OuterVLAN = strconv.Itoa(outer)
+	}
+}
+
 //
 // 3200
 func (sshAction *SshAction) GetRoutes() (routes Routes, err error) {
@@ -213,13 +202,19 @@ func (sshAction *SshAction) GetRoutes() (routes Routes, err error) {
 		case PlatformGAiA:
 			fallthrough
 			
+		case PlatformGaiaEmbedded:
+			fallthrough
+			
+		case PlatformScalable:
+			fallthrough
+			
 		case PlatformSplatCPSHELL:
-			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetRoutes(): PlatformGAiA or PlatformSplatCPSHELL\n") }
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetRoutes(): PlatformGAiA, PlatformGaiaEmbedded, PlatformScalable or PlatformSplatCPSHELL\n") }
 					
 			if sshAction.expertEnter() == nil{
-				result, err = sshAction.execute("ip -o -f inet route 2>&1", 10)
+				result, err = sshAction.execute("ip -o -f inet route 2>&1; ip -o -f inet6 route 2>&1", 10)
 				if err != nil {
-					if sshAction.verbose >= 1 { fmt.Printf("SshAction::GetRoutes(): unable to execute 'ip -o -f inet route 2>&1'\n") }
+					if sshAction.verbose >= 1 { fmt.Printf("SshAction::GetRoutes(): unable to execute 'ip -o -f inet[6] route 2>&1'\n") }
 				}
 			
 				sshAction.expertExit()
@@ -228,19 +223,35 @@ func (sshAction *SshAction) GetRoutes() (routes Routes, err error) {
 		case PlatformExpert:
 			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetRoutes(): PlatformExpert\n") }
 
-			result, err = sshAction.execute("ip -o -f inet route 2>&1", 10)
+			result, err = sshAction.execute("ip -o -f inet route 2>&1; ip -o -f inet6 route 2>&1", 10)
 			if err != nil {
-				if sshAction.verbose >= 1 { fmt.Printf("SshAction::GetRoutes(): unable to execute 'ip -o -f inet route 2>&1'\n") }
+				if sshAction.verbose >= 1 { fmt.Printf("SshAction::GetRoutes(): unable to execute 'ip -o -f inet[6] route 2>&1'\n") }
 			}
 		
 		case PlatformIPSO:
 			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetRoutes(): PlatformIPSO\n") }
 			
-			result, err = sshAction.execute("netstat -rn|grep ' CU '|grep -v '::'", 10)
+			result, err = sshAction.execute("netstat -rn|grep -v '::'", 10)
 			if err != nil {
-				fmt.Printf("SshAction::GetInterfaces(): unable to execute 'netstat -rn|grep ' CU '|grep -v '::''\n")
+				fmt.Printf("SshAction::GetRoutes(): unable to execute 'netstat -rn|grep -v '::''\n")
 			} else {
-				err = sshAction.ipsoRoutes(result, &routes)
+				var warnings parser.Warnings
+				
+				routes, warnings = parser.ParseIPSONetstatRoutes(result, 4)
+				
+				sshAction.warn(warnings)
+			}
+			
+			if err == nil {
+				result, err = sshAction.execute("netstat -rn -f inet6", 10)
+				if err != nil {
+					fmt.Printf("SshAction::GetRoutes(): unable to execute 'netstat -rn -f inet6'\n")
+				} else {
+					routes6, warnings := parser.ParseIPSONetstatRoutes(result, 6)
+					
+					routes = append(routes, routes6...)
+					sshAction.warn(warnings)
+				}
 			}
 
 			sort.Sort(routes)
@@ -262,51 +273,15 @@ func (sshAction *SshAction) GetRoutes() (routes Routes, err error) {
 		return nil, New(3202, err.Error())
 	}
 
-	lines := strings.Split(result, "\n")
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetRoutes(): lines = %q\n", strings.Split(result, "\n")) }
 	
-	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetRoutes(): lines = %q\n", lines) }
+	routes, warnings := parser.ParseIPRoute(result)
 	
-	// go thru each line
-	for _, v := range lines {
-		f := strings.Fields(v)
-		n := len(f)
-
-		if sshAction.verbose > 0 { fmt.Printf("SshAction::GetRoutes(): n = %d, f = %q\n", n,f) }
-		
-		if (n == 5 || n == 7) && f[1] == "via" && f[3] == "dev" {
-			if sshAction.verbose >= 1 { fmt.Printf("'%s' -> '%s'\n", f[0], f[2]) }
-			
-			a  := strings.Split(f[0], "/")
-		
-			if len(a) == 1 {
-				if f[0] != "default" {
-					f[0] = f[0] + "/32"
-					if sshAction.verbose >= 1 { fmt.Printf("SshAction::GetRoutes(): (host-route) '%s' -> '%s'\n", f[0], f[2]) }
-				} else {
-					f[0] = "0.0.0.0/0"
-					if sshAction.verbose >= 1 { fmt.Printf("SshAction::GetRoutes(): (default) '%s' -> '%s'\n", f[0], f[2]) }
-				}
-			}
-			
-			var n NetworkRoute
-			n.Net     = f[0]
-			n.Gateway = f[2]
-			n.Dev     = f[4]
-			
-			_, ipnet, err := net.ParseCIDR(f[0])
-			if err != nil {
-				fmt.Printf("SshAction::GetRoutes(): %q -- %d\n", f, n)
-				fmt.Printf("SshAction::GetRoutes(): '%s' -> '%s'\n", f[0], f[2])
-    			fmt.Printf("SshAction::GetRoutes(): invalid network: %s" + err.Error())
-				
-				return nil, New(3202, err.Error())
-			} else {
-				n.IPNet = *ipnet
-				
-				routes = append(routes, n)
-				
-				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetRoutes(): %s / %s -> %s\n", n.IPNet.IP.String(), n.IPNet.Mask.String(), n.Gateway) }
-			}
+	sshAction.warn(warnings)
+	
+	if sshAction.verbose > 0 {
+		for _, n := range routes {
+			fmt.Printf("SshAction::GetRoutes(): %s / %s -> %s\n", n.IPNet.IP.String(), n.IPNet.Mask.String(), n.Gateway)
 		}
 	}
 	
@@ -316,275 +291,3 @@ func (sshAction *SshAction) GetRoutes() (routes Routes, err error) {
 	
 	return routes, nil
 }
-
-/******************************************************************************************************************
-* IPSO functions
-*
-*/
-
-//
-// 3800
-func (sshAction *SshAction) ipsoInterfaces(result string, logical *LogicalInterfaces, physical *PhysicalInterfaces) (err error) {
-	lines := strings.Split(result, "\n")
-
-	var name	string
-	var phys	string
-	var ip		string
-	var up		bool
-	var vlan	string
-	
-	// go thru each line
-	for _, v := range lines {
-		if v[0] != '\t' && v[0] != ' ' {									// start of interface data
-			if ip != "" && up && phys != "" {
-				if sshAction.verbose > 0 { fmt.Printf("SshAction::ipsoInterfaces(): done; phys = '%s', vlan = '%s', ip = %s\n", phys, vlan, ip) }
-
-				a  := strings.Split(ip, "/")
-				
-				addr := net.ParseIP(a[0])
- 				if addr == nil {
-					fmt.Printf("SshAction::ipsoInterfaces(): invalid address '%s'\n", ip)				
- 				} else {
-					var ni NetworkLogicalInterface
-					
-					if vlan == "" {
-						ni.IfName = phys
-					} else {
-						ni.IfName = phys + "." + vlan
-					}
-					
-					ni.IfIP   = ip
-					ni.Addr   = addr
-	
-					if len(a) == 2 {
-						m, _    := strconv.Atoi(a[1])
-						ni.Mask  = net.CIDRMask(m, 32)
-					} else {
-						ni.Mask  = net.CIDRMask(32, 32)
-					}
-					
-					*logical = append(*logical, ni)
-											
-					if sshAction.verbose >= 1 { fmt.Printf("SshAction::ipsoInterfaces(): ifname = '%s', ip = '%s'\n", ni.IfName, ni.IfIP) }
-				}
-
-				var np NetworkPhysicalInterface
-				
-				np.IfName = phys
-				np.VLAN   = vlan
-				*physical = append(*physical, np)
-
-				if sshAction.verbose >= 1 { fmt.Printf("SshAction::ipsoInterfaces(): done, physical; ifname = '%s', vlan = '%s'\n", np.IfName, np.VLAN) }
-			}
-			
-			i := strings.Split(v, ":")
-
-			name	= i[0]
-			phys	= ""
-			up		= false
-			vlan	= ""
-			ip		= ""
-			
-			if sshAction.verbose > 0 { fmt.Printf("SshAction::ipsoInterfaces(): name = %s\n", name) }
-
-			d := strings.Split(i[1], " ")
-			
-			for idx, vv := range d {
-				//if sshAction.verbose > 0 { fmt.Printf("SshAction::ipsoInterfaces(): vv = %s\n", vv) }
-				
-				if strings.Contains(vv, "flags=") && strings.Contains(vv, "UP") {
-					up = true
-					
-					//if sshAction.verbose > 0 { fmt.Printf("SshAction::ipsoInterfaces(): up = %q\n", up) }
-				} else if strings.Contains(vv, "vlan-id") {
-					vlan = strings.TrimSpace(d[idx + 1])
-					
-					//if sshAction.verbose > 0 { fmt.Printf("SshAction::ipsoInterfaces(): VLAN = %s\n", vlan) }
-				}
-			}
-		} else {																// contiuation of interface data
-			d := strings.Split(strings.TrimSpace(v), " ")
-			n := len(d)
-			
-			for idx, vv := range d {
-				if sshAction.verbose > 0 { fmt.Printf("SshAction::ipsoInterfaces(): idx = %d, n = %d, vv = %s\n", idx, n, vv) }
-				if sshAction.verbose > 0 { fmt.Printf("SshAction::ipsoInterfaces(): d = %q\n", d) }
-				
-				var err error
-				
-				if strings.Contains(vv, "inet") && n >= 5 && ip == "" {
-					// let's find the ip-address/mask
-					_, _, err = net.ParseCIDR(d[idx + 3])
-					if err != nil {
-						_, _, err = net.ParseCIDR(d[idx + 4])
-						if err != nil {
-							if n >= 6 {
-								_, _, err = net.ParseCIDR(d[idx + 5])
-								if err != nil {
-									ip = ""
-								} else {
-									ip = d[idx + 5]
-								}
-							} else {
-								ip = ""
-							}
-						} else {
-							ip = d[idx + 4]
-						}
-					} else {
-						ip = d[idx + 3]
-					}
-					
-					if sshAction.verbose > 0 { fmt.Printf("SshAction::ipsoInterfaces(): inet (1); '%s'\n", ip) }
-				} else if strings.Contains(vv, "inet") && n == 4 && ip == "" {
-					_, _, err = net.ParseCIDR(d[idx + 1])
-					if err != nil {
-						ip = ""
-					} else {
-						ip = d[idx + 1]
-					}
-
-					if sshAction.verbose > 0 { fmt.Printf("SshAction::ipsoInterfaces(): inet (2); '%s'\n", ip) }
-				} else if strings.Contains(vv, "phys") && n >= 2 {
-					phys = strings.TrimSpace(d[idx + 1])
-					//if sshAction.verbose > 0 { fmt.Printf("SshAction::ipsoInterfaces(): phys; '%s'\n", d[idx + 1]) }
-				}
-			}
-		}
-	}
-	
-	return nil
-}
-
-//
-//
-func (sshAction *SshAction) ipsoRoutes(result string, routes *Routes) (err error) {
-	lines := strings.Split(result, "\n")
-	
-	// go thru each line
-	for _, v := range lines {
-		f := strings.Fields(strings.TrimSpace(v))
-		n := len(f)
-		
-		if sshAction.verbose > 0 { fmt.Printf("SshAction::ipsoRoutes(): n = %d, d = %q\n", n, f) }
-		
-		if n == 6 {
-			if sshAction.verbose >= 1 { fmt.Printf("SshAction::ipsoRoutes(): '%s' -> '%s'\n", f[0], f[1]) }
-			
-			a  := strings.Split(f[0], "/")
-		
-			if len(a) == 1 {
-				if f[0] != "default" {
-					f[0] = f[0] + "/32"
-					if sshAction.verbose >= 1 { fmt.Printf("SshAction::ipsoRoutes(): (host-route) '%s' -> '%s'\n", f[0], f[1]) }
-				} else {
-					f[0] = "0.0.0.0/0"
-					if sshAction.verbose >= 1 { fmt.Printf("SshAction::ipsoRoutes(): (default) '%s' -> '%s'\n", f[0], f[1]) }
-				}
-			} else {
-				ii  := strings.Split(a[0], ".")
-				iin := len(ii)
-
-    			if sshAction.verbose >= 1 { fmt.Printf("SshAction::ipsoRoutes(): iin = %d, ii = %q\n", iin, ii) }
-				
-				if iin == 3 {
-					f[0] = ii[0] + "." + ii[1] + "." + ii[2] + ".0" + "/" + a[1]
-				} else if iin == 2 {
-					f[0] = ii[0] + "." + ii[1] + ".0.0" + "/" + a[1]
-				} else if iin == 1 {
-					f[0] = ii[0] + ".0.0.0" + "/" + a[1]
-				}
-
-    			if sshAction.verbose >= 1 { fmt.Printf("SshAction::ipsoRoutes(): f[0] = %s\n", f[0]) }
-			}
-			
-			var n NetworkRoute
-			n.Net     = f[0]
-			n.Gateway = f[1]
-			n.Dev     = f[5]
-			
-			_, ipnet, err := net.ParseCIDR(f[0])
-			if err != nil {
-				fmt.Printf("SshAction::ipsoRoutes(): %q -- %d\n", f, n)
-				fmt.Printf("SshAction::ipsoRoutes(): '%s' -> '%s'\n", f[0], f[1])
-    			fmt.Printf("SshAction::ipsoRoutes(): invalid network: %s\n", err.Error())
-				
-				//return false
-			} else {
-				n.IPNet = *ipnet
-				
-				*routes = append(*routes, n)
-				
-				if sshAction.verbose > 0 { fmt.Printf("SshAction::ipsoRoutes(): %s / %s -> %s\n", n.IPNet.IP.String(), n.IPNet.Mask.String(), n.Gateway) }
-			}
-		}
-	}
-	
-	return nil
-}
-	
-/******************************************************************************************************************
-* helper functions
-*
-*/
-
-//
-//
-func (slice LogicalInterfaces) Len() int {
-    return len(slice)
-}
-
-//
-//
-func (slice LogicalInterfaces) Less(i, j int) bool {
-    return slice[i].IfName < slice[j].IfName
-}
-
-//
-//
-func (slice LogicalInterfaces) Swap(i, j int) {
-    slice[i], slice[j] = slice[j], slice[i]
-}
-
-//
-//
-func (slice PhysicalInterfaces) Len() int {
-    return len(slice)
-}
-
-//
-//
-func (slice PhysicalInterfaces) Less(i, j int) bool {
-    return slice[i].IfName + slice[i].VLAN < slice[j].IfName + slice[j].VLAN
-}
-
-//
-//
-func (slice PhysicalInterfaces) Swap(i, j int) {
-    slice[i], slice[j] = slice[j], slice[i]
-}
-
-//
-//
-func (slice Routes) Len() int {
-    return len(slice)
-}
-
-//
-//
-func (slice Routes) Less(i, j int) bool {
-	addr_i := uint32(slice[i].IPNet.IP[0]) << 24 | uint32(slice[i].IPNet.IP[1]) << 16 | uint32(slice[i].IPNet.IP[2]) << 8 | uint32(slice[i].IPNet.IP[3])
-	addr_j := uint32(slice[j].IPNet.IP[0]) << 24 | uint32(slice[j].IPNet.IP[1]) << 16 | uint32(slice[j].IPNet.IP[2]) << 8 | uint32(slice[j].IPNet.IP[3])
-
-	return addr_i < addr_j
-}
-
-//
-//
-func (slice Routes) Swap(i, j int) {
-    slice[i], slice[j] = slice[j], slice[i]
-}
-
-func ipNetToUint32(ip net.IP) (n uint32) {
-	return uint32(ip[0]) << 24 | uint32(ip[1]) << 16 | uint32(ip[2]) << 8 | uint32(ip[3])
-}
\ No newline at end of file
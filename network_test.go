@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"testing"
+	"github.com/mikejac/ssh.golang/parser"
+)
+
+//
+//
+func TestResolveVLAN(t *testing.T) {
+	vlans, _ := parser.ParseProcVlanConfig("eth1.100 | 100 | eth1\nsvc | 300 | bond0\ncust | 10 | svc\n")
+
+	for _, tt := range []struct {
+		name	string
+		want	NetworkPhysicalInterface
+	}{
+		{"eth1", NetworkPhysicalInterface{IfName: "eth1"}},
+		{"eth1.100", NetworkPhysicalInterface{IfName: "eth1", Parent: "eth1", VLAN: "100"}},
+		{"svc", NetworkPhysicalInterface{IfName: "bond0", Parent: "bond0", VLAN: "300"}},
+		{"cust", NetworkPhysicalInterface{IfName: "bond0", Parent: "svc", VLAN: "10", OuterVLAN: "300"}},
+	} {
+		var ni NetworkPhysicalInterface
+
+		resolveVLAN(&ni, tt.name, vlans)
+
+		if ni.IfName != tt.want.IfName || ni.Parent != tt.want.Parent || ni.VLAN != tt.want.VLAN || ni.OuterVLAN != tt.want.OuterVLAN {
+			t.Errorf("resolveVLAN(%s) = %+v, want %+v", tt.name, ni, tt.want)
+		}
+	}
+}
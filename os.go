@@ -24,8 +24,12 @@ package sshtool
 import (
 	"fmt"
 	"strings"
+	"github.com/mikejac/ssh.golang/parser"
 )
 
+// VersionInfo is defined, and parsed, in the parser package
+type VersionInfo = parser.VersionInfo
+
 type OsClass int
 type OsType  int
 
@@ -36,6 +40,7 @@ const (
 	OsClassSPLAT		OsClass = 3
 	OsClassGAIA		OsClass = 4
 	OsClassXBM			OsClass = 5
+	OsClassGAIAEmbedded	OsClass = 6
 	
 	OsTypeNone      	OsType = 0
 	OsTypeIPSO3_6   	OsType = 1
@@ -47,143 +52,270 @@ const (
 	OsTypeR65_2_6   	OsType = 23
 	OsTypeR70_1     	OsType = 24
 	OsTypeR77_20		OsType = 25
+	OsTypeR77_30		OsType = 26
+	OsTypeR80_10		OsType = 27
+	OsTypeR80_20		OsType = 28
+	OsTypeR80_30		OsType = 29
+	OsTypeR80_40		OsType = 30
+	OsTypeR81			OsType = 31
+	OsTypeR81_10		OsType = 32
+	OsTypeR81_20		OsType = 33
+	OsTypeGaia			OsType = 39			// Gaia release without a constant of its own, see OsVersion
 	OsTypeXOS			OsType = 50
 )
 
+// OsVersion is the release a gateway runs; Major and Minor are taken from the
+// R-number (R80.40 gives 80 and 40), Build is VersionInfo.Build and Take is
+// the installed Jumbo Hotfix take (0 when none or unknown). On Gaia and Gaia
+// Embedded it is derived from the VersionInfo of the same commands
+type OsVersion struct {
+	Class		OsClass
+	Type		OsType
+	Major		int
+	Minor		int
+	Build		int
+	Take		int
+	Kernel		string
+}
+
 //
 //
 func (sshAction *SshAction) GetOS() (osclass OsClass, ostype OsType, err error) {
-	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOS(): begin\n") }
+	version, err := sshAction.GetOSVersion()
+	if version == nil {
+		return OsClassNone, OsTypeNone, err
+	}
+	
+	return version.Class, version.Type, err
+}
 
-	var result string
+//
+// GetOSVersion identifies Gaia from /etc/cp-release, fw ver and (in CLISH) show
+// version all, Gaia Embedded from show software-version; SPLAT, IPSO and
+// Solaris are still told apart by their kernel
+func (sshAction *SshAction) GetOSVersion() (version *OsVersion, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOSVersion(): begin\n") }
+
+	version = &OsVersion{}
+
+	var result		string
+	var clish		string
+	var release	string
+	var fwver		string
+	var take		int
 	
 	switch sshAction.platform {
-		case PlatformGAiA:
+		case PlatformGAiA, PlatformGaiaEmbedded, PlatformScalable:
+			cmd := "show version all"
+			
+			if sshAction.platform == PlatformGaiaEmbedded {
+				cmd = "show software-version"
+			}
+			
+			clish, err = sshAction.execute(cmd, 10)
+			if err != nil {
+				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOSVersion(): unable to execute '%s'\n", cmd) }
+			}
+			
 			fallthrough
 			
 		case PlatformSplatCPSHELL:
-			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOS(): PlatformGAiA or PlatformSplatCPSHELL\n") }
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOSVersion(): PlatformGAiA, PlatformGaiaEmbedded, PlatformScalable or PlatformSplatCPSHELL\n") }
 					
 			if sshAction.expertEnter() == nil {
-				result, err = sshAction.execute("uname -r 2>&1", 5)
-				if err == nil {
-					result = strings.TrimSpace(result)
-					
-					if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOS(): result = %s\n", result) }					
-				}
+				result, release, fwver, take, err = sshAction.expertGetOS()
 			
 				sshAction.expertExit()
 			}
 		
 		case PlatformExpert:
-			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOS(): PlatformExpert\n") }
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOSVersion(): PlatformExpert\n") }
 
-			result, err = sshAction.execute("uname -r 2>&1", 5)
-			if err == nil {
-				result = strings.TrimSpace(result)
-				
-				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOS(): result = %s\n", result) }					
-			}
+			result, release, fwver, take, err = sshAction.expertGetOS()
 		
 		case PlatformIPSO:
-			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOS(): PlatformIPSO\n") }
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOSVersion(): PlatformIPSO\n") }
 
 			result, err = sshAction.execute("uname -r", 5)
 			if err == nil {
 				result = strings.TrimSpace(result)
 				
-				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOS(): result = %s\n", result) }					
+				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOSVersion(): result = %s\n", result) }					
 			}
 		
 		case PlatformXBM:
-			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOS(): PlatformXBM\n") }
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOSVersion(): PlatformXBM\n") }
 			
-			osclass, ostype, err = sshAction.xbmGetInfo()
+			version.Class, version.Type, err = sshAction.xbmGetInfo()
 			
-			return osclass, ostype, err
+			return version, err
 			
 		default:
-			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOS(): unknown platform\n") }
-			return OsClassNone, OsTypeNone, New(2001, "platform unknown")
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOSVersion(): unknown platform\n") }
+			return nil, New(2001, "platform unknown")
+	}
+	
+	version.Kernel = result
+	
+	var info VersionInfo
+	
+	if sshAction.embedded {
+		parser.ParseSoftwareVersion(&info, clish)
+	} else {
+		parser.ParseShowVersionAll(&info, clish)
+		parser.ParseCpRelease(&info, release)
+	}
+	
+	parser.ParseFwVer(&info, fwver)
+	
+	info.Take = take
+	
+	if sshAction.embedded && info.Product == "" {
+		// logged in at the busybox prompt: no 'show software-version', but fw
+		// ver still tells the release
+		info.Product = "Check Point Gaia Embedded"
 	}
 	
+	if osVersionFromInfo(version, &info) {
+		if sshAction.verbose >= 1 { fmt.Printf("SshAction::GetOSVersion(): %s R%d.%d, build %d, take %d\n", info.Product, version.Major, version.Minor, version.Build, version.Take) }
+		
+		return version, nil
+	}
+	
+	if sshAction.embedded {
+		return version, New(2004, "unable to determine gaia embedded version")
+	}
+	
+	version.Class, version.Type, err = sshAction.osFromKernel(result)
+	
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOSVersion(): end\n") }					
+
+	return version, err
+}
+
+//
+//
+func (sshAction *SshAction) expertGetOS() (kernel string, release string, fwver string, take int, err error) {
+	kernel, err = sshAction.execute("uname -r 2>&1", 5)
+	if err != nil {
+		return "", "", "", 0, err
+	}
+	
+	kernel = strings.TrimSpace(kernel)
+	
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::expertGetOS(): result = %s\n", kernel) }					
+	
+	// the remaining commands are missing on older releases, so failures are not fatal
+	if release, err = sshAction.execute("cat /etc/cp-release 2>&1", 5); err != nil {
+		release = ""
+	}
+	
+	if fwver, err = sshAction.execute("fw ver 2>&1", 20); err != nil {
+		fwver = ""
+	}
+	
+	take = sshAction.expertGetTake()
+	
+	return kernel, release, fwver, take, nil
+}
+
+//
+// expertGetTake gives the Jumbo Hotfix take of 'cpinfo -y all', 0 when none is
+// installed or cpinfo fails
+func (sshAction *SshAction) expertGetTake() (take int) {
+	result, err := sshAction.execute("cpinfo -y all 2>&1", 120)
+	if err != nil {
+		return 0
+	}
+	
+	return parser.ParseJumboTake(result)
+}
+
+//
+//
+func (sshAction *SshAction) osFromKernel(result string) (osclass OsClass, ostype OsType, err error) {
 	if strings.Contains(result, "2.4.21-21cp") {
 		osclass = OsClassSPLAT
 		ostype  = OsTypeR65_2_4
-		if sshAction.verbose >= 1 { fmt.Println("SshAction::GetOS(): OsTypeR65_2_4") }
+		if sshAction.verbose >= 1 { fmt.Println("SshAction::osFromKernel(): OsTypeR65_2_4") }
 	} else if strings.Contains(result, "2.4.21-21cpsmp") {
 		osclass = OsClassSPLAT
 		ostype  = OsTypeR65_2_4
-		if sshAction.verbose >= 1 { fmt.Println("SshAction::GetOS(): OsTypeR65_2_4") }
+		if sshAction.verbose >= 1 { fmt.Println("SshAction::osFromKernel(): OsTypeR65_2_4") }
 	} else if strings.Contains(result, "2.6.18-22cp") {
 		osclass = OsClassSPLAT
 		ostype  = OsTypeR65_2_6
-		if sshAction.verbose >= 1 { fmt.Println("SshAction::GetOS(): OsTypeR65_2_6") }
+		if sshAction.verbose >= 1 { fmt.Println("SshAction::osFromKernel(): OsTypeR65_2_6") }
 	} else if strings.Contains(result, "2.6.18-92cp") {
 		osclass = OsClassSPLAT
 		ostype  = OsTypeR70_1
-		if sshAction.verbose >= 1 { fmt.Println("SshAction::GetOS(): OsTypeR70_1") }
+		if sshAction.verbose >= 1 { fmt.Println("SshAction::osFromKernel(): OsTypeR70_1") }
 	} else if strings.Contains(result, "2.4.9-42cp") {
 		osclass = OsClassSPLAT
 		ostype  = OsTypeR55	
-		if sshAction.verbose >= 1 { fmt.Println("SshAction::GetOS(): OsTypeR55") }
+		if sshAction.verbose >= 1 { fmt.Println("SshAction::osFromKernel(): OsTypeR55") }
 	} else if strings.Contains(result, "3.8") {
 		osclass = OsClassIPSO
 		ostype  = OsTypeIPSO3_8
-		if sshAction.verbose >= 1 { fmt.Println("SshAction::GetOS(): OsTypeIPSO3_8") }
+		if sshAction.verbose >= 1 { fmt.Println("SshAction::osFromKernel(): OsTypeIPSO3_8") }
 	} else if strings.Contains(result, "3.7") {
 		osclass = OsClassIPSO
 		ostype  = OsTypeIPSO3_7
-		if sshAction.verbose >= 1 { fmt.Println("SshAction::GetOS(): OsTypeIPSO3_7") }
+		if sshAction.verbose >= 1 { fmt.Println("SshAction::osFromKernel(): OsTypeIPSO3_7") }
 	} else if strings.Contains(result, "3.6") {
 		osclass = OsClassIPSO
 		ostype  = OsTypeIPSO3_6
-		if sshAction.verbose >= 1 { fmt.Println("SshAction::GetOS(): OsTypeIPSO3_6") }
+		if sshAction.verbose >= 1 { fmt.Println("SshAction::osFromKernel(): OsTypeIPSO3_6") }
 	} else if strings.Contains(result, "5.8") {
 		osclass = OsClassSOLARIS
 		ostype  = OsTypeSOLARIS
-		if sshAction.verbose >= 1 { fmt.Println("SshAction::GetOS(): OsTypeSOLARIS") }
+		if sshAction.verbose >= 1 { fmt.Println("SshAction::osFromKernel(): OsTypeSOLARIS") }
 	} else if strings.Contains(result, "Running commands is not allowed") {
-		if sshAction.verbose > 0 { fmt.Println("SshAction::GetOS(): none; running commands is not allowed") }
+		if sshAction.verbose > 0 { fmt.Println("SshAction::osFromKernel(): none; running commands is not allowed") }
 		err = New(2002, "running commands is not allowed")
 	} else {
 		err = New(2003, "unexpected")
-		if sshAction.verbose > 0 { fmt.Println("SshAction::GetOS(): unknown") }
+		if sshAction.verbose > 0 { fmt.Println("SshAction::osFromKernel(): unknown") }
 	}
 	
-	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOS(): end\n") }					
-
 	return osclass, ostype, err
 }
 
 //
 //
-func (sshAction *SshAction) GetInfo() (fwver string, platform string, err error) {
+func (sshAction *SshAction) GetInfo() (info *VersionInfo, err error) {
 	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): begin\n") }
 
-	var result string
+	info = &VersionInfo{}
 	
 	switch sshAction.platform {
-		case PlatformGAiA:
+		case PlatformGAiA, PlatformScalable:
+			result, err := sshAction.execute("show version all", 10)
+			if err == nil {
+				parser.ParseShowVersionAll(info, result)
+			} else {
+				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): unable to execute 'show version all'\n") }
+			}
+			
 			fallthrough
 			
-		case PlatformSplatCPSHELL:
-			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): PlatformGAiA or PlatformSplatCPSHELL\n") }
-					
-			if sshAction.expertEnter() == nil {
-				result, err = sshAction.execute("fw ver 2>&1", 20)
+		case PlatformGaiaEmbedded:
+			if sshAction.platform == PlatformGaiaEmbedded {
+				result, err := sshAction.execute("show software-version", 10)
 				if err == nil {
-					fwver = strings.TrimSpace(result)
-					
-					if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): result = %s\n", fwver) }					
+					parser.ParseSoftwareVersion(info, result)
+				} else {
+					if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): unable to execute 'show software-version'\n") }
 				}
+			}
 			
-				result, err = sshAction.execute("cat /etc/cp-release 2>&1", 20)
-				if err == nil {
-					platform = strings.TrimSpace(result)
+			fallthrough
+			
+		case PlatformSplatCPSHELL:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): PlatformGAiA, PlatformGaiaEmbedded, PlatformScalable or PlatformSplatCPSHELL\n") }
 					
-					if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): result = %s\n", platform) }					
-				}
+			if sshAction.expertEnter() == nil {
+				err = sshAction.expertGetInfo(info)
 
 				sshAction.expertExit()
 			}
@@ -191,45 +323,101 @@ func (sshAction *SshAction) GetInfo() (fwver string, platform string, err error)
 		case PlatformExpert:
 			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): PlatformExpert\n") }
 
-			result, err = sshAction.execute("fw ver 2>&1", 20)
-			if err == nil {
-				fwver = strings.TrimSpace(result)
-				
-				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): result = %s\n", fwver) }					
-
-				result, err = sshAction.execute("cat /etc/cp-release 2>&1", 20)
-				if err == nil {
-					platform = strings.TrimSpace(result)
-					
-					if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): result = %s\n", platform) }					
-				}
-			}
+			err = sshAction.expertGetInfo(info)
 		
 		case PlatformIPSO:
 			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): PlatformIPSO\n") }
 
+			var result string
+			
 			result, err = sshAction.execute("fw ver", 20)
 			if err == nil {
-				fwver = strings.TrimSpace(result)
+				info.FwVer = strings.TrimSpace(result)
 				
-				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): result = %s\n", fwver) }					
+				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): result = %s\n", info.FwVer) }					
+
+				parser.ParseFwVer(info, info.FwVer)
 			}
 			
-			platform = "IPSO"
+			if result, e := sshAction.execute("uname -r", 5); e == nil {
+				info.Kernel = strings.TrimSpace(result)
+			}
+			
+			info.Product = "IPSO"
+			info.Release = "IPSO"
 		
 		case PlatformXBM:
 			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): PlatformXBM\n") }
-			return "", "", New(2100, "platform xbm")
+			return nil, New(2100, "platform xbm")
 			
 		default:
 			fmt.Printf("SshAction::GetInfo(): unknown platform\n")
-			return "", "", New(2101, "platform unknown")
+			return nil, New(2101, "platform unknown")
 	}
 	
 	
 	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetInfo(): end\n") }					
 
-	return fwver, platform, err
+	return info, err
+}
+
+//
+// expertGetInfo fills in what 'show version all' did not provide; only a
+// failing fw ver is an error since the other commands vary between releases
+func (sshAction *SshAction) expertGetInfo(info *VersionInfo) (err error) {
+	var result string
+	
+	result, err = sshAction.execute("fw ver 2>&1", 20)
+	if err != nil {
+		return err
+	}
+	
+	info.FwVer = strings.TrimSpace(result)
+	
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::expertGetInfo(): result = %s\n", info.FwVer) }					
+
+	parser.ParseFwVer(info, info.FwVer)
+
+	if sshAction.embedded {
+		// busybox userland: no /etc/cp-release, cpstat os or cpinfo
+		if info.Product == "" {
+			info.Product = "Check Point Gaia Embedded"
+		}
+		
+		if result, err = sshAction.execute("uname -r 2>&1", 5); err == nil {
+			info.Kernel = strings.TrimSpace(result)
+		}
+		
+		return nil
+	}
+	
+	if result, err = sshAction.execute("cat /etc/cp-release 2>&1", 20); err == nil {
+		info.Release = strings.TrimSpace(result)
+		
+		if sshAction.verbose > 0 { fmt.Printf("SshAction::expertGetInfo(): result = %s\n", info.Release) }					
+		
+		parser.ParseCpRelease(info, info.Release)
+	}
+	
+	if info.Kernel == "" {
+		if result, err = sshAction.execute("uname -r 2>&1", 5); err == nil {
+			info.Kernel = strings.TrimSpace(result)
+		}
+	}
+	
+	if info.Edition == "" {
+		if result, err = sshAction.execute("uname -m 2>&1", 5); err == nil {
+			info.Edition = editionFromMachine(strings.TrimSpace(result))
+		}
+	}
+	
+	if result, err = sshAction.execute("cpstat os 2>&1", 20); err == nil {
+		parser.ParseCpstatOs(info, result)
+	}
+	
+	info.Take = sshAction.expertGetTake()
+	
+	return nil
 }
 
 //
@@ -239,29 +427,86 @@ func (sshAction *SshAction) xbmGetInfo() (osclass OsClass, ostype OsType, err er
 	
 	result, err = sshAction.execute("show version", 5)
 	if err == nil {
-		lines := strings.Split(result, "\n")
-		
-		if sshAction.verbose > 0 { fmt.Printf("SshAction::xbmGetInfo(): lines = %q\n", lines) }
+		if sshAction.verbose > 0 { fmt.Printf("SshAction::xbmGetInfo(): lines = %q\n", strings.Split(result, "\n")) }
 		
-		// go thru each line
-		for _, v := range lines {
-			if strings.Contains(v, "Version:") {
-				f := strings.Fields(v)
-				n := len(f)
+		if product, _, found := parser.ParseXOSVersion(result); found {
+			if product == "XOS" {
+				osclass	= OsClassXBM
+				ostype		= OsTypeXOS
+			} else {
+				if sshAction.verbose > 0 { fmt.Printf("SshAction::xbmGetInfo(): invalid version string\n") }
 				
-				if n >= 3 && f[1] == "XOS" {
-					osclass	= OsClassXBM
-					ostype		= OsTypeXOS
-					
-					break
-				} else {
-					if sshAction.verbose > 0 { fmt.Printf("SshAction::xbmGetInfo(): invalid version string\n") }
-					
-					return osclass, ostype, New(2200, "invalid version string")
-				}
+				return osclass, ostype, New(2200, "invalid version string")
 			}
 		}
 	}
 
 	return osclass, ostype, err
-}
\ No newline at end of file
+}
+
+/******************************************************************************************************************
+* helper functions
+*
+*/
+
+//
+// osVersionFromInfo fills in version from info when info names a Gaia or Gaia
+// Embedded release
+func osVersionFromInfo(version *OsVersion, info *VersionInfo) (bool) {
+	if info.Version == "" || !strings.Contains(info.Product, "Gaia") {
+		return false
+	}
+	
+	version.Class = OsClassGAIA
+	
+	if strings.Contains(info.Product, "Embedded") {
+		version.Class = OsClassGAIAEmbedded
+	}
+	
+	version.Major	= info.Major
+	version.Minor	= info.Minor
+	version.Build	= info.Build
+	version.Take	= info.Take
+	version.Type	= osTypeFromVersion(info.Major, info.Minor)
+	
+	return true
+}
+
+//
+//
+func osTypeFromVersion(major int, minor int) (OsType) {
+	switch major * 100 + minor {
+		case 7720:
+			return OsTypeR77_20
+		case 7730:
+			return OsTypeR77_30
+		case 8010:
+			return OsTypeR80_10
+		case 8020:
+			return OsTypeR80_20
+		case 8030:
+			return OsTypeR80_30
+		case 8040:
+			return OsTypeR80_40
+		case 8100:
+			return OsTypeR81
+		case 8110:
+			return OsTypeR81_10
+		case 8120:
+			return OsTypeR81_20
+	}
+	
+	return OsTypeGaia
+}
+
+//
+//
+func editionFromMachine(machine string) (string) {
+	if strings.HasSuffix(machine, "64") {
+		return "64-bit"
+	} else if machine != "" {
+		return "32-bit"
+	}
+	
+	return ""
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+
+package sshtool
+
+import (
+	"strings"
+	"testing"
+)
+
+//
+//
+func TestGetOSVersionEmbeddedExpert(t *testing.T) {
+	// logged in at the busybox prompt of a Gaia Embedded appliance
+	sshAction, shell := newStubSession(PlatformExpert, map[string]string{
+		"uname -r 2>&1":	"2.6.35.14-ar\n",
+		"fw ver 2>&1":		"This is Check Point's software version R80.20.40 - Build 992002123\n",
+	})
+	defer shell.Close()
+
+	sshAction.embedded = true
+
+	version, err := sshAction.GetOSVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version.Class != OsClassGAIAEmbedded || version.Major != 80 || version.Minor != 20 || version.Build != 992002123 || version.Type != OsTypeR80_20 {
+		t.Errorf("version = %+v", version)
+	}
+
+	if version.Kernel != "2.6.35.14-ar" {
+		t.Errorf("Kernel = %q", version.Kernel)
+	}
+}
+
+//
+//
+func TestGetOSVersionAndInfoTake(t *testing.T) {
+	outputs := map[string]string{
+		"uname -r 2>&1":			"3.10.0-1160.15.2cpx86_64\n",
+		"uname -m 2>&1":			"x86_64\n",
+		"cat /etc/cp-release 2>&1":	"Check Point Gaia R81.20\n",
+		"fw ver 2>&1":				"This is Check Point's software version R81.20 - Build 631\n",
+		// every package is listed; the highest take counts
+		"cpinfo -y all 2>&1":		"[CPFC]\n\tHOTFIX_R81_20_JUMBO_HF_MAIN\tTake:  26\n\n[FW1]\n\tHOTFIX_R81_20_JUMBO_HF_MAIN\tTake:  53\n",
+	}
+
+	sshAction, shell := newStubSession(PlatformExpert, outputs)
+	defer shell.Close()
+
+	version, err := sshAction.GetOSVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := sshAction.GetInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version.Class != OsClassGAIA || version.Type != OsTypeR81_20 || version.Build != 631 {
+		t.Errorf("version = %+v", version)
+	}
+
+	if version.Take != 53 || info.Take != 53 {
+		t.Errorf("GetOSVersion() take = %d, GetInfo() take = %d, want 53", version.Take, info.Take)
+	}
+
+	for _, cmd := range shell.commands {
+		if strings.HasPrefix(cmd, "cpinfo") && cmd != "cpinfo -y all 2>&1" {
+			t.Errorf("ran %q", cmd)
+		}
+	}
+}
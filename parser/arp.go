@@ -0,0 +1,275 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"net"
+	"regexp"
+	"strings"
+)
+
+// ArpEntry is one neighbour (ARP or IPv6 ND) entry. Proxy entries are the
+// addresses the Check Point answers ARP for, from local.arp or 'fw ctl arp'.
+type ArpEntry struct {
+	IP			string
+	MAC			string
+	IfName		string			// for 'fw ctl arp' the interface address instead of its name
+	State		string			// e.g. "reachable", "stale", "permanent", "incomplete"
+	Family		int
+	Proxy		bool
+	Source		string			// "kernel", "local.arp" or "fw ctl arp"
+}
+
+type ArpTable []ArpEntry
+
+var (
+	reIPSOArp		= regexp.MustCompile(`^\S+\s+\(([^)]+)\)\s+at\s+(\S+)(?:\s+on\s+(\S+))?(.*)$`)
+	reFwCtlArp		= regexp.MustCompile(`\(([^)]+)\)\s+at\s+(\S+)(?:\s+interface\s+(\S+))?`)
+)
+
+//
+//
+func (slice ArpTable) Len() int {
+	return len(slice)
+}
+
+//
+//
+func (slice ArpTable) Less(i, j int) bool {
+	if slice[i].Family != slice[j].Family {
+		return slice[i].Family < slice[j].Family
+	}
+
+	a := net.ParseIP(slice[i].IP)
+	b := net.ParseIP(slice[j].IP)
+
+	if c := compareIP(a, b); c != 0 {
+		return c < 0
+	}
+
+	return slice[i].Source < slice[j].Source
+}
+
+//
+//
+func (slice ArpTable) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+//
+// compareIP orders unparsable addresses first
+func compareIP(a net.IP, b net.IP) (int) {
+	if a4 := a.To4(); a4 != nil {
+		a = a4
+	}
+
+	if b4 := b.To4(); b4 != nil {
+		b = b4
+	}
+
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return int(a[i]) - int(b[i])
+		}
+	}
+
+	return 0
+}
+
+//
+// ParseIPNeigh reads 'ip neigh show'
+//
+//	10.0.0.1 dev eth0 lladdr 00:11:22:33:44:55 REACHABLE
+//	fe80::1 dev eth0 lladdr 00:11:22:33:44:55 router STALE
+//	10.0.0.9 dev eth0  FAILED
+func ParseIPNeigh(result string) (arp ArpTable, warnings Warnings) {
+	for l, v := range strings.Split(result, "\n") {
+		f := strings.Fields(v)
+		n := len(f)
+
+		if n == 0 || net.ParseIP(f[0]) == nil {
+			continue
+		}
+
+		if n < 3 {
+			warnings.Add("ParseIPNeigh", l, v, "neighbour without device or state")
+			continue
+		}
+
+		e := ArpEntry{IP: f[0], Family: IPFamily(net.ParseIP(f[0])), Source: "kernel"}
+
+		for i := 1; i < n; i++ {
+			switch f[i] {
+				case "dev":
+					if i + 1 < n { e.IfName = f[i + 1]; i++ }
+				case "lladdr":
+					if i + 1 < n { e.MAC = strings.ToLower(f[i + 1]); i++ }
+				case "proxy":
+					e.Proxy = true
+				case "router":
+				default:
+					e.State = strings.ToLower(f[i])
+			}
+		}
+
+		arp = append(arp, e)
+	}
+
+	return arp, warnings
+}
+
+//
+// ParseArpAn reads IPSO (FreeBSD) 'arp -an'
+//
+//	? (10.0.0.1) at 0:11:22:33:44:55 on eth-s1p1c0 [ethernet]
+//	? (10.0.0.9) at (incomplete) on eth-s1p1c0 [ethernet]
+//	? (10.0.0.7) at 0:11:22:33:44:66 on eth-s1p1c0 permanent published [ethernet]
+func ParseArpAn(result string) (arp ArpTable, warnings Warnings) {
+	for l, v := range strings.Split(result, "\n") {
+		m := reIPSOArp.FindStringSubmatch(strings.TrimSpace(v))
+		if m == nil {
+			if strings.TrimSpace(v) != "" {
+				warnings.Add("ParseArpAn", l, v, "unrecognised entry")
+			}
+
+			continue
+		}
+
+		e := ArpEntry{IP: m[1], IfName: m[3], Family: IPFamily(net.ParseIP(m[1])), Source: "kernel"}
+
+		if m[2] == "(incomplete)" || m[2] == "incomplete" {
+			e.State = "incomplete"
+		} else {
+			e.MAC = normaliseMAC(m[2])
+		}
+
+		rest := m[4]
+
+		if strings.Contains(rest, "permanent") {
+			e.State = "permanent"
+		}
+
+		if strings.Contains(rest, "published") {
+			e.Proxy = true
+		}
+
+		arp = append(arp, e)
+	}
+
+	return arp, warnings
+}
+
+//
+// ParseLocalArp reads $FWDIR/conf/local.arp, '<ip> <mac>' per line
+func ParseLocalArp(result string) (arp ArpTable, warnings Warnings) {
+	for l, v := range strings.Split(result, "\n") {
+		f := strings.Fields(v)
+
+		if len(f) == 0 || strings.HasPrefix(f[0], "#") {
+			continue
+		}
+
+		if len(f) < 2 || net.ParseIP(f[0]) == nil {
+			warnings.Add("ParseLocalArp", l, v, "expected '<ip> <mac>'")
+			continue
+		}
+
+		arp = append(arp, ArpEntry{IP: f[0], MAC: normaliseMAC(f[1]), State: "permanent", Family: IPFamily(net.ParseIP(f[0])), Proxy: true, Source: "local.arp"})
+	}
+
+	return arp, warnings
+}
+
+//
+// ParseFwCtlArp reads 'fw ctl arp'
+//
+//	(192.168.1.10) at 00-1c-7f-aa-bb-cc interface 192.168.1.1
+func ParseFwCtlArp(result string) (arp ArpTable) {
+	for _, v := range strings.Split(result, "\n") {
+		m := reFwCtlArp.FindStringSubmatch(v)
+		if m == nil || net.ParseIP(m[1]) == nil {
+			continue
+		}
+
+		arp = append(arp, ArpEntry{IP: m[1], MAC: normaliseMAC(m[2]), IfName: m[3], Family: IPFamily(net.ParseIP(m[1])), Proxy: true, Source: "fw ctl arp"})
+	}
+
+	return arp
+}
+
+//
+// ParseXOSArp reads CrossBeam XOS 'show arp'. The column layout differs
+// between XOS releases, so every row holding an IP and a MAC address is
+// taken, with the first remaining field as the interface.
+func ParseXOSArp(result string) (arp ArpTable) {
+	for _, v := range strings.Split(result, "\n") {
+		var e ArpEntry
+
+		for _, f := range strings.Fields(v) {
+			if e.IP == "" && net.ParseIP(f) != nil {
+				e.IP = f
+			} else if _, err := net.ParseMAC(f); err == nil && e.MAC == "" {
+				e.MAC = normaliseMAC(f)
+			} else if e.IfName == "" && e.IP != "" {
+				e.IfName = f
+			}
+		}
+
+		if e.IP == "" || e.MAC == "" {
+			continue
+		}
+
+		e.Family = IPFamily(net.ParseIP(e.IP))
+		e.Source = "kernel"
+
+		arp = append(arp, e)
+	}
+
+	return arp
+}
+
+//
+// normaliseMAC returns aa:bb:cc:dd:ee:ff for the 0:1c:7f:.. and 00-1c-7f-..
+// notations; anything else is returned unchanged
+func normaliseMAC(s string) (string) {
+	p := strings.FieldsFunc(s, func(r rune) (bool) { return r == ':' || r == '-' })
+
+	if len(p) != 6 {
+		return s
+	}
+
+	for i := range p {
+		if len(p[i]) == 1 {
+			p[i] = "0" + p[i]
+		}
+	}
+
+	if mac, err := net.ParseMAC(strings.Join(p, ":")); err == nil {
+		return mac.String()
+	}
+
+	return s
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+//
+//
+type BondSlave struct {
+	IfName			string
+	MIIStatus		string			// "up" or "down"
+	Speed			int				// Mb/s
+	Duplex			string
+	LinkFailures	int
+	AggregatorID	int				// 802.3ad only
+}
+
+//
+//
+type NetworkBond struct {
+	IfName			string
+	Mode			string			// e.g. "IEEE 802.3ad Dynamic link aggregation", "fault-tolerance (active-backup)"
+	MIIStatus		string
+	ActiveSlave		string			// active-backup only
+	LACPRate		string			// 802.3ad only
+	AggregatorID	int				// 802.3ad active aggregator
+	PartnerMAC		string			// 802.3ad active aggregator
+	Slaves			[]BondSlave
+}
+
+type Bonds []NetworkBond
+
+//
+//
+type NetworkBridge struct {
+	IfName			string
+	Members		[]string
+}
+
+type Bridges []NetworkBridge
+
+//
+// ParseProcBonding reads /proc/net/bonding/<bond> files, each preceded by a
+// '== <bond>' line
+func ParseProcBonding(result string) (bonds Bonds) {
+	var bond	*NetworkBond
+	var slave	*BondSlave
+	var active	bool							// inside 'Active Aggregator Info'
+
+	for _, v := range strings.Split(result, "\n") {
+		t := strings.TrimSpace(v)
+
+		if strings.HasPrefix(t, "== ") {
+			bonds  = append(bonds, NetworkBond{IfName: strings.TrimPrefix(t, "== ")})
+			bond   = &bonds[len(bonds) - 1]
+			slave  = nil
+			active = false
+
+			continue
+		}
+
+		if bond == nil {
+			continue
+		}
+
+		if t == "Active Aggregator Info:" {
+			active = true
+			continue
+		}
+
+		f := strings.SplitN(t, ":", 2)
+
+		if len(f) != 2 {
+			continue
+		}
+
+		key   := strings.TrimSpace(f[0])
+		value := strings.TrimSpace(f[1])
+
+		if key == "Slave Interface" {
+			bond.Slaves = append(bond.Slaves, BondSlave{IfName: value})
+			slave  = &bond.Slaves[len(bond.Slaves) - 1]
+			active = false
+
+			continue
+		}
+
+		if slave != nil {
+			switch key {
+				case "MII Status":
+					slave.MIIStatus = value
+				case "Speed":
+					slave.Speed, _ = strconv.Atoi(strings.TrimSuffix(value, " Mbps"))
+				case "Duplex":
+					slave.Duplex = value
+				case "Link Failure Count":
+					slave.LinkFailures, _ = strconv.Atoi(value)
+				case "Aggregator ID":
+					slave.AggregatorID, _ = strconv.Atoi(value)
+			}
+
+			continue
+		}
+
+		switch key {
+			case "Bonding Mode":
+				bond.Mode = value
+			case "MII Status":
+				bond.MIIStatus = value
+			case "Currently Active Slave":
+				bond.ActiveSlave = value
+			case "LACP rate":
+				bond.LACPRate = value
+			case "Aggregator ID":
+				if active {
+					bond.AggregatorID, _ = strconv.Atoi(value)
+				}
+			case "Partner Mac Address":
+				if active {
+					bond.PartnerMAC = value
+				}
+		}
+	}
+
+	return bonds
+}
+
+//
+// ParseBridgeMembers reads a '== <bridge>' line per bridge, followed by
+// the names in /sys/class/net/<bridge>/brif
+func ParseBridgeMembers(result string) (bridges Bridges) {
+	for _, v := range strings.Split(result, "\n") {
+		t := strings.TrimSpace(v)
+
+		if strings.HasPrefix(t, "== ") {
+			bridges = append(bridges, NetworkBridge{IfName: strings.TrimPrefix(t, "== ")})
+			continue
+		}
+
+		if len(bridges) == 0 || t == "" {
+			continue
+		}
+
+		b := &bridges[len(bridges) - 1]
+
+		b.Members = append(b.Members, strings.Fields(t)...)
+	}
+
+	return bridges
+}
@@ -0,0 +1,421 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+//
+//
+type CphaMember struct {
+	ID			int
+	Local		bool
+	UniqueIP	string
+	Load		int				// assigned load in percent
+	State		string			// lower case, e.g. "active", "standby", "down"
+	Name		string			// R80.x and later only
+}
+
+// CphaDevice is a critical device (pnote) from cphaprob list
+type CphaDevice struct {
+	Name		string
+	State		string			// "ok" or "problem"
+	Timeout		string
+}
+
+// CphaInterface is a monitored interface from cphaprob -a if
+type CphaInterface struct {
+	Name		string
+	State		string			// lower case, e.g. "up", "down"
+	Sync		bool
+	Secured		bool
+	Transport	string			// "multicast", "broadcast" or "unicast" when reported
+}
+
+//
+//
+type CphaVirtualInterface struct {
+	Name		string
+	IP			string
+}
+
+//
+//
+type CphaData struct {
+	Status		string				// state of the local member
+	Mode		string				// e.g. "High Availability (Active Up)", "Load Sharing (Multicast)"
+	HA			bool				// true for High Availability, false for Load Sharing
+	Transport	string				// "multicast" or "unicast" (CCP / load sharing mode)
+	SyncStatus	string				// e.g. "ok", "problem"; empty if unknown
+
+	Members				[]CphaMember
+	ActivePnotes		[]string
+	Devices				[]CphaDevice
+	Interfaces			[]CphaInterface
+	VirtualInterfaces	[]CphaVirtualInterface
+
+	SGMs	[]SGMState				// Scalable Platform only
+}
+
+// SGMState is one Security Group Member as reported by 'asg monitor'
+type SGMState struct {
+	ID			string			// e.g. "1_01"
+	Site		int				// chassis on 61k/64k, site on Maestro
+	Member		int
+	State		string			// lower case, e.g. "up", "down", "detached"
+	Local		bool
+}
+
+//
+// ParseCphaprobStat reads both the R7x member table
+//
+//	Number     Unique Address  Assigned Load   State
+//	1 (local)  10.0.0.1        100%            Active
+//
+// and the R80.x one, which adds a Name column and uses upper case states
+//
+//	ID         Unique Address  Assigned Load   State          Name
+//	1 (local)  10.0.0.1        100%            ACTIVE         gw1
+func ParseCphaprobStat(result string) (cpha *CphaData, warnings Warnings) {
+	cpha = &CphaData{}
+	
+	pnotes := false
+	
+	for l, v := range strings.Split(result, "\n") {
+		t := strings.TrimSpace(v)
+		
+		if strings.Contains(t, "not started") {
+			cpha.Status = "not_started"
+			
+			return cpha, warnings
+		}
+		
+		if strings.HasPrefix(t, "Cluster Mode:") {
+			cpha.Mode = strings.TrimSpace(strings.TrimPrefix(t, "Cluster Mode:"))
+			cpha.HA   = strings.HasPrefix(cpha.Mode, "High Availability")
+			
+			lower := strings.ToLower(cpha.Mode)
+			
+			if strings.Contains(lower, "unicast") {
+				cpha.Transport = "unicast"
+			} else if strings.Contains(lower, "multicast") {
+				cpha.Transport = "multicast"
+			}
+			
+			continue
+		}
+		
+		if strings.HasPrefix(t, "Active PNOTEs:") {
+			if p := strings.TrimSpace(strings.TrimPrefix(t, "Active PNOTEs:")); p != "" && p != "None" {
+				for _, n := range strings.Split(p, ",") {
+					cpha.ActivePnotes = append(cpha.ActivePnotes, strings.TrimSpace(n))
+				}
+			} else if p == "" {
+				pnotes = true
+			}
+			
+			continue
+		}
+		
+		if pnotes {
+			// R80.x may list the active pnotes on the lines that follow
+			if t == "" {
+				pnotes = false
+			} else if t != "None" {
+				cpha.ActivePnotes = append(cpha.ActivePnotes, t)
+			}
+			
+			continue
+		}
+		
+		f := strings.Fields(t)
+		n := len(f)
+		
+		if n == 0 {
+			continue
+		}
+		
+		id, err := strconv.Atoi(f[0])
+		if err != nil {
+			continue
+		}
+		
+		var m CphaMember
+		m.ID = id
+		
+		i := 1
+		
+		if n > 1 && f[1] == "(local)" {
+			m.Local = true
+			i++
+		}
+		
+		if i + 2 >= n {
+			warnings.Add("ParseCphaprobStat", l, v, "member line with too few fields")
+			continue
+		}
+		
+		m.UniqueIP	= f[i]
+		m.Load, _	= strconv.Atoi(strings.TrimSuffix(f[i + 1], "%"))
+		m.State		= strings.ToLower(f[i + 2])
+		
+		if i + 3 < n {
+			m.Name = strings.Join(f[i + 3:], " ")
+		}
+		
+		cpha.Members = append(cpha.Members, m)
+		
+		if m.Local {
+			cpha.Status = m.State
+		}
+	}
+	
+	return cpha, warnings
+}
+
+//
+// ParseCphaprobList adds the Device Name / Current state blocks; R7x lists
+// every device, R80.x only those reporting a problem
+func ParseCphaprobList(cpha *CphaData, result string) (warnings Warnings) {
+	var d *CphaDevice
+	
+	for l, v := range strings.Split(result, "\n") {
+		f := strings.SplitN(strings.TrimSpace(v), ":", 2)
+		
+		if len(f) != 2 {
+			continue
+		}
+		
+		value := strings.TrimSpace(f[1])
+		
+		switch f[0] {
+			case "Device Name":
+				cpha.Devices = append(cpha.Devices, CphaDevice{Name: value})
+				d = &cpha.Devices[len(cpha.Devices) - 1]
+				
+			case "Current state":
+				if d == nil {
+					warnings.Add("ParseCphaprobList", l, v, "state without device")
+				} else {
+					d.State = strings.ToLower(value)
+					
+					if d.State != "ok" && !containsString(cpha.ActivePnotes, d.Name) {
+						cpha.ActivePnotes = append(cpha.ActivePnotes, d.Name)
+					}
+				}
+				
+			case "Timeout":
+				if d != nil {
+					d.Timeout = value
+				}
+		}
+	}
+	
+	return warnings
+}
+
+//
+// ParseCphaprobIf adds the monitored interfaces, R7x
+//
+//	eth1       UP                    sync(secured), multicast
+//
+// or R80.x
+//
+//	eth1 (S)             UP
+//
+// followed by the virtual cluster interfaces
+func ParseCphaprobIf(cpha *CphaData, result string) (warnings Warnings) {
+	virtual := false
+	
+	for l, v := range strings.Split(result, "\n") {
+		t := strings.TrimSpace(v)
+		
+		if strings.HasPrefix(t, "Virtual cluster interfaces") {
+			virtual = true
+			continue
+		}
+		
+		if strings.HasPrefix(t, "CCP mode:") {
+			lower := strings.ToLower(t)
+			
+			if strings.Contains(lower, "unicast") {
+				cpha.Transport = "unicast"
+			} else if strings.Contains(lower, "multicast") {
+				cpha.Transport = "multicast"
+			}
+			
+			continue
+		}
+		
+		f := strings.Fields(t)
+		n := len(f)
+		
+		if n < 2 || strings.HasSuffix(f[0], ":") || f[0] == "S" || f[0] == "Interface" {
+			continue
+		}
+		
+		if virtual {
+			if net.ParseIP(f[1]) != nil {
+				cpha.VirtualInterfaces = append(cpha.VirtualInterfaces, CphaVirtualInterface{Name: f[0], IP: f[1]})
+			}
+			
+			continue
+		}
+		
+		var i CphaInterface
+		i.Name = f[0]
+		
+		k := 1
+		
+		if strings.HasPrefix(f[1], "(") {
+			flags := strings.Trim(f[1], "()")
+			i.Sync = flags == "S" || strings.HasPrefix(flags, "S ") || strings.Contains(flags, "S,")
+			k++
+		}
+		
+		if k >= n {
+			warnings.Add("ParseCphaprobIf", l, v, "interface without state")
+			continue
+		}
+		
+		state := strings.ToLower(f[k])
+		
+		if state != "up" && state != "down" && state != "disconnected" && state != "problem" {
+			continue
+		}
+		
+		i.State = state
+		
+		rest := strings.ToLower(strings.Join(f[k + 1:], " "))
+		
+		if strings.Contains(rest, "non sync") {
+			i.Sync = false
+		} else if strings.Contains(rest, "sync") {
+			i.Sync = true
+		}
+		
+		i.Secured = strings.Contains(rest, "(secured)") || i.Sync
+		
+		for _, t := range []string{"multicast", "broadcast", "unicast"} {
+			if strings.Contains(rest, t) {
+				i.Transport = t
+			}
+		}
+		
+		cpha.Interfaces = append(cpha.Interfaces, i)
+	}
+	
+	return warnings
+}
+
+//
+//
+func ParseCphaprobSyncstat(cpha *CphaData, result string) {
+	for _, v := range strings.Split(result, "\n") {
+		t := strings.TrimSpace(v)
+		
+		if strings.HasPrefix(t, "Sync status:") {
+			cpha.SyncStatus = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(t, "Sync status:")))
+			
+			return
+		}
+	}
+}
+
+//
+//
+func containsString(list []string, s string) (bool) {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	
+	return false
+}
+
+//
+// SGMID formats a member ID, e.g. "1_01"
+func SGMID(site int, member int) (string) {
+	return fmt.Sprintf("%d_%02d", site, member)
+}
+
+//
+// ParseAsgMonitor reads the per-chassis (or per-site) member tables, e.g.
+//
+//	| Chassis 1                  ACTIVE      |
+//	| SGM ID   State       Process           |
+//	| 1 (local)  UP        Enforcing Security |
+//	| 2          DOWN      Inactive since ... |
+func ParseAsgMonitor(result string) (sgms []SGMState, warnings Warnings) {
+	site := 1
+
+	for l, v := range strings.Split(result, "\n") {
+		f := strings.Fields(strings.Trim(strings.TrimSpace(v), "|"))
+		n := len(f)
+
+		if n < 2 {
+			continue
+		}
+
+		if f[0] == "Chassis" || f[0] == "Site" {
+			if s, err := strconv.Atoi(f[1]); err == nil {
+				site = s
+			}
+
+			continue
+		}
+
+		member, err := strconv.Atoi(f[0])
+		if err != nil {
+			continue
+		}
+
+		var sgm SGMState
+		sgm.Site	= site
+		sgm.Member	= member
+		sgm.ID		= SGMID(site, member)
+
+		i := 1
+
+		if f[1] == "(local)" {
+			sgm.Local = true
+			i++
+		}
+
+		if i >= n {
+			warnings.Add("ParseAsgMonitor", l, v, "member without state")
+			continue
+		}
+
+		sgm.State = strings.ToLower(f[i])
+
+		sgms = append(sgms, sgm)
+	}
+
+	return sgms, warnings
+}
+
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+type VAPGroup struct {
+	Name	string
+	Count	int
+}
+
+type VAPGroups []VAPGroup
+
+//
+// ParseVAPGroups reads CrossBeam XOS 'show vap-group'
+//
+//	VAP Group         : fw1
+//	VAP Count         : 4
+func ParseVAPGroups(text string) (vapGroups VAPGroups, warnings Warnings) {
+	var vapGroup string
+
+	for l, v := range strings.Split(text, "\n") {
+		f := strings.Split(v, ":")
+
+		if len(f) != 2 {
+			continue
+		}
+
+		if strings.HasPrefix(v, "VAP Group") {
+			vapGroup = strings.TrimSpace(f[1])
+		} else if strings.HasPrefix(v, "VAP Count") {
+			count, err := strconv.Atoi(strings.TrimSpace(f[1]))
+
+			if err != nil || vapGroup == "" {
+				warnings.Add("ParseVAPGroups", l, v, "VAP Count without VAP Group or number")
+				continue
+			}
+
+			// we have the info we want from this VAP so store it
+			var vap VAPGroup
+			vap.Name	= vapGroup
+			vap.Count	= count
+
+			vapGroups = append(vapGroups, vap)
+		}
+	}
+
+	return vapGroups, warnings
+}
+
+//
+// ParseXOSVersion reads the 'Version:' line of CrossBeam 'show version', e.g.
+// 'Version: XOS 9.5.0'. found is false when there is no such line.
+func ParseXOSVersion(text string) (product string, version string, found bool) {
+	for _, v := range strings.Split(text, "\n") {
+		if !strings.Contains(v, "Version:") {
+			continue
+		}
+
+		f := strings.Fields(v)
+
+		if len(f) >= 2 {
+			product = f[1]
+		}
+
+		if len(f) >= 3 {
+			version = f[2]
+		}
+
+		return product, version, true
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package parser turns the text output of gateway commands into data. The
+// functions do no I/O, so they work as well on live output as on saved output,
+// e.g. from a cpinfo file. sshtool re-exports the types.
+//
+// Parsers never panic on unexpected input. Lines they cannot read are skipped
+// and reported as Warnings next to the result.
+//
+// Output without a parser of its own can be read with a TextFSM template, see
+// Template.
+package parser
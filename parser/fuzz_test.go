@@ -0,0 +1,441 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+// The fuzz targets are seeded with the golden inputs. Besides not panicking,
+// parsers must report warnings against lines that exist in their input.
+
+//
+// seed adds the testdata captures to the corpus of f
+func seed(f *testing.F, names ...string) {
+	for _, name := range names {
+		f.Add(testdata(name))
+	}
+}
+
+//
+// checkWarnings fails t for a warning of another parser or one whose line is
+// not in text
+func checkWarnings(t *testing.T, parser string, text string, warnings Warnings) {
+	lines := strings.Split(text, "\n")
+
+	for _, w := range warnings {
+		if w.Parser != parser {
+			t.Errorf("warning of %s from %s: %s", w.Parser, parser, w)
+		}
+
+		if w.Line < 1 || w.Line > len(lines) || !strings.Contains(lines[w.Line - 1], w.Text) {
+			t.Errorf("warning for a line not in the input: %s", w)
+		}
+	}
+}
+
+//
+//
+func FuzzParseIPAddr(f *testing.F) {
+	seed(f, "gaia/ip-addr.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseIPAddr(text)
+		checkWarnings(t, "ParseIPAddr", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseIPRoute(f *testing.F) {
+	seed(f, "gaia/ip-route.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseIPRoute(text)
+		checkWarnings(t, "ParseIPRoute", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseIPSOIfconfig(f *testing.F) {
+	seed(f, "ipso/ifconfig.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, _, warnings := ParseIPSOIfconfig(text)
+		checkWarnings(t, "ParseIPSOIfconfig", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseIPSONetstatRoutes(f *testing.F) {
+	f.Add(testdata("ipso/netstat-rn.txt"), 4)
+	f.Add(testdata("ipso/netstat-rn-inet6.txt"), 6)
+	f.Fuzz(func(t *testing.T, text string, family int) {
+		_, warnings := ParseIPSONetstatRoutes(text, family)
+		checkWarnings(t, "ParseIPSONetstatRoutes", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseCphaprobStat(f *testing.F) {
+	seed(f, "gaia/cphaprob-stat.txt", "splat/cphaprob-stat.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseCphaprobStat(text)
+		checkWarnings(t, "ParseCphaprobStat", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseCphaprobList(f *testing.F) {
+	seed(f, "gaia/cphaprob-list.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		warnings := ParseCphaprobList(&CphaData{}, text)
+		checkWarnings(t, "ParseCphaprobList", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseCphaprobIf(f *testing.F) {
+	seed(f, "gaia/cphaprob-a-if.txt", "splat/cphaprob-a-if.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		warnings := ParseCphaprobIf(&CphaData{}, text)
+		checkWarnings(t, "ParseCphaprobIf", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseCphaprobSyncstat(f *testing.F) {
+	seed(f, "gaia/cphaprob-syncstat.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		ParseCphaprobSyncstat(&CphaData{}, text)
+	})
+}
+
+//
+//
+func FuzzParseAsgMonitor(f *testing.F) {
+	seed(f, "scalable/asg-monitor.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseAsgMonitor(text)
+		checkWarnings(t, "ParseAsgMonitor", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseVAPGroups(f *testing.F) {
+	seed(f, "xos/show-vap-group.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseVAPGroups(text)
+		checkWarnings(t, "ParseVAPGroups", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseXOSVersion(f *testing.F) {
+	seed(f, "xos/show-version.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		ParseXOSVersion(text)
+	})
+}
+
+//
+//
+func FuzzParseIPNeigh(f *testing.F) {
+	seed(f, "gaia/ip-neigh.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseIPNeigh(text)
+		checkWarnings(t, "ParseIPNeigh", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseArpAn(f *testing.F) {
+	seed(f, "ipso/arp-an.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseArpAn(text)
+		checkWarnings(t, "ParseArpAn", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseLocalArp(f *testing.F) {
+	seed(f, "gaia/local-arp.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseLocalArp(text)
+		checkWarnings(t, "ParseLocalArp", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseFwCtlArp(f *testing.F) {
+	seed(f, "gaia/fw-ctl-arp.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		ParseFwCtlArp(text)
+	})
+}
+
+//
+//
+func FuzzParseXOSArp(f *testing.F) {
+	seed(f, "xos/show-arp.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		ParseXOSArp(text)
+	})
+}
+
+//
+//
+func FuzzParseProcBonding(f *testing.F) {
+	seed(f, "gaia/proc-bonding.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		ParseProcBonding(text)
+	})
+}
+
+//
+//
+func FuzzParseBridgeMembers(f *testing.F) {
+	seed(f, "gaia/bridge-members.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		ParseBridgeMembers(text)
+	})
+}
+
+//
+//
+func FuzzParseProcVlanConfig(f *testing.F) {
+	seed(f, "gaia/proc-vlan-config.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseProcVlanConfig(text)
+		checkWarnings(t, "ParseProcVlanConfig", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseClishVlans(f *testing.F) {
+	seed(f, "gaia/show-configuration-interface.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseClishVlans(text)
+		checkWarnings(t, "ParseClishVlans", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseShowRoute(f *testing.F) {
+	seed(f, "gaia/show-route.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseShowRoute(text)
+		checkWarnings(t, "ParseShowRoute", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseOSPFNeighbors(f *testing.F) {
+	seed(f, "gaia/show-ospf-neighbors.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseOSPFNeighbors(text)
+		checkWarnings(t, "ParseOSPFNeighbors", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseOSPFInterfaces(f *testing.F) {
+	seed(f, "gaia/show-ospf-interfaces.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseOSPFInterfaces(text)
+		checkWarnings(t, "ParseOSPFInterfaces", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseBGPPeers(f *testing.F) {
+	seed(f, "gaia/show-bgp-peers.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseBGPPeers(text)
+		checkWarnings(t, "ParseBGPPeers", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseStaticRouteConfig(f *testing.F) {
+	seed(f, "gaia/show-configuration-static-route.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseStaticRouteConfig(text)
+		checkWarnings(t, "ParseStaticRouteConfig", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseCpstatMg(f *testing.F) {
+	seed(f, "gaia/cpstat-mg.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		ParseCpstatMg(&ManagementInfo{}, text)
+	})
+}
+
+//
+//
+func FuzzParseCpwdAdminList(f *testing.F) {
+	seed(f, "gaia/cpwd-admin-list.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseCpwdAdminList(text)
+		checkWarnings(t, "ParseCpwdAdminList", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseMdsstat(f *testing.F) {
+	seed(f, "gaia/mdsstat.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseMdsstat(text)
+		checkWarnings(t, "ParseMdsstat", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseVsxStat(f *testing.F) {
+	seed(f, "gaia/vsx-stat-v.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseVsxStat(text)
+		checkWarnings(t, "ParseVsxStat", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseGAll(f *testing.F) {
+	seed(f, "scalable/g-all.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseGAll(text)
+		checkWarnings(t, "ParseGAll", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseIPLink(f *testing.F) {
+	seed(f, "gaia/ip-link.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, warnings := ParseIPLink(text)
+		checkWarnings(t, "ParseIPLink", text, warnings)
+	})
+}
+
+//
+//
+func FuzzParseEthtool(f *testing.F) {
+	seed(f, "gaia/ethtool.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		physical, _ := ParseIPLink(testdata("gaia/ip-link.txt"))
+		ParseEthtool(physical, text)
+	})
+}
+
+//
+//
+func FuzzParseIPSOPhysical(f *testing.F) {
+	f.Add(testdata("ipso/ifconfig.txt"), testdata("ipso/netstat-in.txt"))
+	f.Fuzz(func(t *testing.T, ifconfig string, netstat string) {
+		ParseIPSOPhysical(ifconfig, netstat)
+	})
+}
+
+//
+//
+func FuzzParseXOSInterfaces(f *testing.F) {
+	seed(f, "xos/show-interface.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		ParseXOSInterfaces(text)
+	})
+}
+
+//
+//
+func FuzzParseJumboTake(f *testing.F) {
+	seed(f, "gaia/cpinfo-y-all.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		ParseJumboTake(text)
+	})
+}
+
+//
+//
+func FuzzParseShowVersionAll(f *testing.F) {
+	seed(f, "gaia/show-version-all.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		ParseShowVersionAll(&VersionInfo{}, text)
+	})
+}
+
+//
+//
+func FuzzParseCpRelease(f *testing.F) {
+	seed(f, "gaia/cp-release.txt", "splat/cp-release.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		ParseCpRelease(&VersionInfo{}, text)
+	})
+}
+
+//
+//
+func FuzzParseFwVer(f *testing.F) {
+	seed(f, "gaia/fw-ver.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		ParseFwVer(&VersionInfo{}, text)
+	})
+}
+
+//
+//
+func FuzzParseCpstatOs(f *testing.F) {
+	seed(f, "gaia/cpstat-os.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		ParseCpstatOs(&VersionInfo{}, text)
+	})
+}
+
+//
+//
+func FuzzParseSoftwareVersion(f *testing.F) {
+	seed(f, "embedded/show-software-version.txt")
+	f.Fuzz(func(t *testing.T, text string) {
+		ParseSoftwareVersion(&VersionInfo{}, text)
+	})
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	reGAllHeader	= regexp.MustCompile(`^-\*-\s*\d+\s+blades?:\s*(.*?)\s*-\*-\s*$`)
+)
+
+//
+// ParseGAll splits g_all output into per-member output. Members printing the
+// same output are grouped under one '-*- 2 blades: 1_01 1_02 -*-' header.
+func ParseGAll(result string) (results map[string]string, warnings Warnings) {
+	results = make(map[string]string)
+
+	var ids	[]string
+	var out	[]string
+
+	flush := func() {
+		for _, id := range ids {
+			results[id] = strings.TrimSpace(strings.Join(out, "\n"))
+		}
+	}
+
+	for l, v := range strings.Split(result, "\n") {
+		v = strings.TrimRight(v, "\r")
+
+		if m := reGAllHeader.FindStringSubmatch(v); m != nil {
+			flush()
+
+			ids = strings.Fields(m[1])
+			out = nil
+
+			continue
+		}
+
+		if ids == nil && strings.TrimSpace(v) != "" {
+			warnings.Add("ParseGAll", l, v, "output before the first member header")
+			continue
+		}
+
+		out = append(out, v)
+	}
+
+	flush()
+
+	return results, warnings
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ProcessState is one line of 'cpwd_admin list'
+type ProcessState struct {
+	PID			int
+	State		string			// E = executing, T = terminated
+	Starts		int
+}
+
+// ManagementInfo describes the Check Point roles of a machine and, when it is
+// a management server, the state of the management in the current context
+type ManagementInfo struct {
+	IsGateway		bool
+	IsManagement	bool
+	IsMDS			bool
+
+	Product		string			// 'cpstat mg' product name
+	Started		bool
+	ActiveStatus	string			// e.g. "active", "standby"
+
+	Processes		map[string]ProcessState		// keyed by cpwd application name, e.g. "FWM", "CPM"
+	LockOwner		string			// owner of the R7x database lock, empty if unlocked
+}
+
+// Domain is one row of 'mdsstat'; Type is "MDS" for the MDS itself and "CMA"
+// for domain management servers
+type Domain struct {
+	Type			string
+	Name			string
+	IP				string
+	Processes		map[string]string	// keyed by process name, e.g. "FWM": "up 1234"
+}
+
+type Domains []Domain
+
+//
+//
+func ParseCpstatMg(info *ManagementInfo, result string) {
+	for _, v := range strings.Split(result, "\n") {
+		f := strings.SplitN(v, ":", 2)
+
+		if len(f) != 2 {
+			continue
+		}
+
+		value := strings.TrimSpace(f[1])
+
+		switch strings.TrimSpace(f[0]) {
+			case "Product name":
+				info.Product = value
+			case "Is started":
+				info.Started = value == "1"
+			case "Active status":
+				info.ActiveStatus = strings.ToLower(value)
+		}
+	}
+}
+
+//
+// ParseCpwdAdminList reads
+//
+//	APP        PID    STAT  #START  START_TIME             MON  COMMAND
+//	FWM        2345   E     1       [12:00:00] 1/1/2021    Y    fwm mgmt
+func ParseCpwdAdminList(result string) (processes map[string]ProcessState, warnings Warnings) {
+	processes = make(map[string]ProcessState)
+
+	for l, v := range strings.Split(result, "\n") {
+		f := strings.Fields(v)
+
+		if len(f) < 4 || f[0] == "APP" {
+			continue
+		}
+
+		var p ProcessState
+		var err error
+
+		if p.PID, err = strconv.Atoi(f[1]); err != nil {
+			warnings.Add("ParseCpwdAdminList", l, v, "invalid PID")
+			continue
+		}
+
+		p.State		= f[2]
+		p.Starts, _	= strconv.Atoi(f[3])
+
+		processes[f[0]] = p
+	}
+
+	return processes, warnings
+}
+
+//
+// ParseMdsstat reads the process table of 'mdsstat', taking the column names
+// from its header row
+func ParseMdsstat(result string) (domains Domains, warnings Warnings) {
+	var header []string
+
+	for l, v := range strings.Split(result, "\n") {
+		v = strings.TrimSpace(v)
+
+		if !strings.HasPrefix(v, "|") {
+			continue
+		}
+
+		f := strings.Split(strings.Trim(v, "|"), "|")
+
+		for i := range f {
+			f[i] = strings.TrimSpace(f[i])
+		}
+
+		if len(f) < 3 {
+			continue
+		}
+
+		if f[0] == "Type" {
+			header = f
+			continue
+		}
+
+		if f[0] != "MDS" && f[0] != "CMA" {
+			continue
+		}
+
+		if header == nil {
+			warnings.Add("ParseMdsstat", l, v, "row before the header")
+			continue
+		}
+
+		var d Domain
+		d.Type		= f[0]
+		d.Name		= f[1]
+		d.IP		= f[2]
+		d.Processes	= make(map[string]string)
+
+		for i := 3; i < len(f) && i < len(header); i++ {
+			d.Processes[header[i]] = f[i]
+		}
+
+		domains = append(domains, d)
+	}
+
+	return domains, warnings
+}
@@ -0,0 +1,560 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"bytes"
+	"net"
+	"strconv"
+	"strings"
+)
+
+//
+//
+type NetworkLogicalInterface struct {
+	IfName	string
+	IfIP	string
+	Addr	net.IP
+	Mask	net.IPMask
+	Family	int				// 4 or 6
+}
+
+type LogicalInterfaces []NetworkLogicalInterface
+
+//
+//
+type NetworkPhysicalInterface struct {
+	IfName	string				// physical device, e.g. "eth1" for "eth1.100"
+	VLAN	string				// VLAN ID; the inner tag for QinQ
+	OuterVLAN	string			// QinQ outer tag, empty otherwise
+	Parent	string				// device the VLAN is stacked on, e.g. "eth1.100" for "eth1.100.200"
+	
+	// filled in by GetPhyInterfaceDetails
+	AdminUp		bool
+	OperUp		bool
+	MAC			string
+	MTU			int
+	Speed		int				// Mb/s, 0 when unknown
+	Duplex		string			// "full", "half" or empty when unknown
+	Driver		string
+	RxErrors	uint64
+	RxDropped	uint64
+	TxErrors	uint64
+	TxDropped	uint64
+	
+	// filled in by LinkMembership
+	Kind		string			// "bond", "bridge" or empty for plain interfaces
+	Master		string			// bond or bridge this interface is a member of
+	Members		[]string		// slaves of a bond, ports of a bridge
+}
+
+type PhysicalInterfaces []NetworkPhysicalInterface
+
+//
+//
+type NetworkRoute struct {
+	Net			string
+	Gateway	string
+	Dev			string
+	IPNet		net.IPNet
+	Family		int				// 4 or 6
+	Protocol	string			// e.g. "kernel", "boot", "static", "zebra"; IPSO: "static", "connected" or empty
+	Metric		int
+	Src			string			// preferred source address, Linux only
+	Scope		string			// "global", "link" or "host"
+	Flags		string			// Linux: extra flags like "onlink"; IPSO: netstat flags like "UGS"
+}
+
+type Routes []NetworkRoute
+
+//
+// ParseIPAddr reads 'ip -o -f inet addr' and 'ip -o -f inet6 addr'
+//
+//	2: eth0    inet 10.0.0.1/24 brd 10.0.0.255 scope global eth0
+//
+// Loopback and IPv6 link-local addresses are left out.
+func ParseIPAddr(text string) (logical LogicalInterfaces, warnings Warnings) {
+	for l, v := range strings.Split(text, "\n") {
+		f := strings.Fields(v)
+		n := len(f)
+
+		if n == 0 {
+			continue
+		}
+
+		if n <= 4 {
+			warnings.Add("ParseIPAddr", l, v, "too few fields")
+			continue
+		}
+
+		if strings.HasPrefix(f[1], "lo") {
+			continue
+		}
+
+		a := strings.Split(f[3], "/")
+
+		addr := net.ParseIP(a[0])
+		if addr == nil {
+			warnings.Add("ParseIPAddr", l, v, "invalid address")
+			continue
+		}
+
+		if addr.IsLinkLocalUnicast() {
+			// fe80::/10 exists on every IPv6 interface and says nothing about topology
+			continue
+		}
+
+		var ni NetworkLogicalInterface
+		ni.IfName = f[1]
+		ni.IfIP   = f[3]
+		ni.Addr   = addr
+		ni.Family = IPFamily(addr)
+
+		if ni.Mask = parseMask(a, IPFamilyBits(ni.Family)); ni.Mask == nil {
+			warnings.Add("ParseIPAddr", l, v, "invalid prefix length")
+			continue
+		}
+
+		logical = append(logical, ni)
+	}
+
+	return logical, warnings
+}
+
+//
+// ParseIPRoute reads 'ip -o -f inet route' and 'ip -o -f inet6 route'; routes
+// without a gateway are the connected ones
+//
+//	10.1.0.0/16 via 10.0.0.2 dev eth0 proto static metric 100
+//	10.0.0.0/24 dev eth0 proto kernel scope link src 10.0.0.1
+//
+// IPv6 link-local and multicast routes exist on every interface and are left out.
+func ParseIPRoute(text string) (routes Routes, warnings Warnings) {
+	for l, v := range strings.Split(text, "\n") {
+		f := strings.Fields(v)
+		n := len(f)
+
+		var r NetworkRoute
+		var a []string
+
+		if n >= 5 && f[1] == "via" && f[3] == "dev" {
+			r.Gateway = f[2]
+			r.Dev     = f[4]
+			a         = f[5:]
+		} else if n >= 3 && f[1] == "dev" {
+			r.Dev = f[2]
+			a     = f[3:]
+		} else {
+			continue
+		}
+
+		parseIPRouteAttributes(&r, a)
+
+		// the gateway, the network or the source address tells the family
+		family := 4
+
+		if strings.Contains(r.Gateway + f[0] + r.Src, ":") {
+			family = 6
+		}
+
+		if !strings.Contains(f[0], "/") {
+			if f[0] != "default" {
+				f[0] = f[0] + "/" + strconv.Itoa(IPFamilyBits(family))
+			} else {
+				f[0] = DefaultRoute(family)
+			}
+		}
+
+		_, ipnet, err := net.ParseCIDR(f[0])
+		if err != nil {
+			warnings.Add("ParseIPRoute", l, v, "invalid network")
+			continue
+		}
+
+		if r.Gateway == "" && (ipnet.IP.IsLinkLocalUnicast() || ipnet.IP.IsMulticast()) {
+			continue
+		}
+
+		r.Net    = f[0]
+		r.Family = family
+		r.IPNet  = *ipnet
+
+		routes = append(routes, r)
+	}
+
+	return routes, warnings
+}
+
+//
+// ParseIPSOIfconfig reads IPSO 'ifconfig -a'. Logical interfaces carry the
+// addresses, e.g. 'eth-s1p1c0' on physical 'eth-s1p1', with 'vlan-id' when
+// tagged; only logical interfaces that are up and have an address count.
+func ParseIPSOIfconfig(text string) (logical LogicalInterfaces, physical PhysicalInterfaces, warnings Warnings) {
+	var phys	string
+	var ip		string
+	var ip6		[]string
+	var up		bool
+	var vlan	string
+
+	done := func() {
+		if (ip == "" && len(ip6) == 0) || !up || phys == "" {
+			return
+		}
+
+		ifname := phys
+
+		if vlan != "" {
+			ifname = phys + "." + vlan
+		}
+
+		// only addresses that passed net.ParseCIDR are collected
+		if ip != "" {
+			addr, ipnet, _ := net.ParseCIDR(ip)
+
+			logical = append(logical, NetworkLogicalInterface{IfName: ifname, IfIP: ip, Addr: addr, Mask: ipnet.Mask, Family: 4})
+		}
+
+		for _, cidr := range ip6 {
+			addr, ipnet, _ := net.ParseCIDR(cidr)
+
+			logical = append(logical, NetworkLogicalInterface{IfName: ifname, IfIP: cidr, Addr: addr, Mask: ipnet.Mask, Family: 6})
+		}
+
+		physical = append(physical, NetworkPhysicalInterface{IfName: phys, VLAN: vlan})
+	}
+
+	for l, v := range strings.Split(text, "\n") {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+
+		if v[0] != '\t' && v[0] != ' ' {									// start of interface data
+			done()
+
+			i := strings.Split(v, ":")
+
+			phys	= ""
+			up		= false
+			vlan	= ""
+			ip		= ""
+			ip6		= nil
+
+			if len(i) < 2 {
+				warnings.Add("ParseIPSOIfconfig", l, v, "interface line without ':'")
+				continue
+			}
+
+			d := strings.Split(i[1], " ")
+
+			for idx, vv := range d {
+				if strings.Contains(vv, "flags=") && strings.Contains(vv, "UP") {
+					up = true
+				} else if strings.Contains(vv, "vlan-id") {
+					if idx + 1 < len(d) {
+						vlan = strings.TrimSpace(d[idx + 1])
+					} else {
+						warnings.Add("ParseIPSOIfconfig", l, v, "vlan-id without value")
+					}
+				}
+			}
+		} else {																// continuation of interface data
+			d := strings.Fields(v)
+			n := len(d)
+
+			for idx, vv := range d {
+				if vv == "inet6" {
+					// 'inet6 mtu 1500 2001:db8::1/64'; link-local addresses are skipped
+					for _, c := range d[idx + 1:] {
+						if a, _, err := net.ParseCIDR(c); err == nil && !a.IsLinkLocalUnicast() {
+							ip6 = append(ip6, c)
+						}
+					}
+
+					break
+				} else if vv == "inet" && ip == "" {
+					// the address/mask is 1 word after 'inet' or, after 'mtu <n>', 3 to 5
+					for k := 1; k <= 5 && idx + k < n; k++ {
+						if a, _, err := net.ParseCIDR(d[idx + k]); err == nil && a.To4() != nil {
+							ip = d[idx + k]
+							break
+						}
+					}
+
+					if ip == "" {
+						warnings.Add("ParseIPSOIfconfig", l, v, "inet without address")
+					}
+				} else if strings.Contains(vv, "phys") && n >= 2 {
+					if idx + 1 < n {
+						phys = strings.TrimSpace(d[idx + 1])
+					} else {
+						warnings.Add("ParseIPSOIfconfig", l, v, "phys without interface")
+					}
+				}
+			}
+		}
+	}
+
+	done()
+
+	return logical, physical, warnings
+}
+
+//
+// ParseIPSONetstatRoutes reads the routes of IPSO 'netstat -rn' for family,
+// connected as well as static and learned ones
+//
+//	Destination        Gateway            Flags     Refs     Use  Netif
+//	10.1/16            10.0.0.2           UGS         0        0  eth-s1p1c0
+func ParseIPSONetstatRoutes(text string, family int) (routes Routes, warnings Warnings) {
+	for l, v := range strings.Split(text, "\n") {
+		f := strings.Fields(strings.TrimSpace(v))
+
+		// the table headers have six columns too; link-local IPv6 routes carry
+		// their interface, e.g. fe80::%eth-s1p1c0/64
+		if len(f) != 6 || f[0] == "Destination" || strings.Contains(f[0], "%") {
+			continue
+		}
+
+		a := strings.Split(f[0], "/")
+
+		if len(a) == 1 {
+			if f[0] != "default" {
+				f[0] = f[0] + "/" + strconv.Itoa(IPFamilyBits(family))
+			} else {
+				f[0] = DefaultRoute(family)
+			}
+		} else if family == 4 {
+			// netstat abbreviates IPv4 networks, e.g. 10.1/16
+			ii := strings.Split(a[0], ".")
+
+			for len(ii) < 4 {
+				ii = append(ii, "0")
+			}
+
+			f[0] = strings.Join(ii, ".") + "/" + a[1]
+		}
+
+		_, ipnet, err := net.ParseCIDR(f[0])
+		if err != nil {
+			warnings.Add("ParseIPSONetstatRoutes", l, v, "invalid network")
+			continue
+		}
+
+		if ipnet.IP.IsLoopback() {
+			continue
+		}
+
+		var r NetworkRoute
+		r.Net     = f[0]
+		r.Gateway = f[1]
+		r.Dev     = f[5]
+		r.Family  = family
+		r.Flags   = f[2]
+		r.IPNet   = *ipnet
+
+		parseIPSORouteFlags(&r)
+
+		routes = append(routes, r)
+	}
+
+	return routes, warnings
+}
+
+/******************************************************************************************************************
+* helper functions
+*
+*/
+
+//
+// parseMask returns the mask of a split 'address/length', the host mask when
+// there is no length, and nil for a length that is not valid for bits
+func parseMask(a []string, bits int) (net.IPMask) {
+	if len(a) != 2 {
+		return net.CIDRMask(bits, bits)
+	}
+
+	m, err := strconv.Atoi(a[1])
+	if err != nil {
+		return nil
+	}
+
+	return net.CIDRMask(m, bits)
+}
+
+//
+// parseIPRouteAttributes reads what follows 'via <gw> dev <if>' in 'ip route',
+// e.g. 'proto static scope link src 10.0.0.1 metric 100 onlink'. iproute2
+// leaves out 'proto boot' and 'scope global'.
+func parseIPRouteAttributes(n *NetworkRoute, f []string) {
+	var flags []string
+	
+	n.Protocol = "boot"
+	n.Scope    = "global"
+	
+	for i := 0; i < len(f); i++ {
+		switch f[i] {
+			case "proto", "scope", "src", "metric", "table", "pref", "mtu", "expires", "realm", "weight":
+				if i + 1 >= len(f) {
+					break
+				}
+				
+				switch f[i] {
+					case "proto":
+						n.Protocol = f[i + 1]
+					case "scope":
+						n.Scope = f[i + 1]
+					case "src":
+						n.Src = f[i + 1]
+					case "metric":
+						n.Metric, _ = strconv.Atoi(f[i + 1])
+				}
+				
+				i++
+				
+			default:
+				flags = append(flags, f[i])
+		}
+	}
+	
+	n.Flags = strings.Join(flags, ",")
+}
+
+//
+// parseIPSORouteFlags derives protocol and scope from the netstat flags
+// (U up, G gateway, H host, S static)
+func parseIPSORouteFlags(n *NetworkRoute) {
+	if strings.Contains(n.Flags, "S") {
+		n.Protocol = "static"
+	} else if !strings.Contains(n.Flags, "G") {
+		n.Protocol = "connected"
+	}
+	
+	if strings.Contains(n.Flags, "H") && !strings.Contains(n.Flags, "G") {
+		n.Scope = "host"
+	} else if !strings.Contains(n.Flags, "G") {
+		n.Scope = "link"
+	} else {
+		n.Scope = "global"
+	}
+}
+
+//
+//
+func (slice LogicalInterfaces) Len() int {
+    return len(slice)
+}
+
+//
+//
+func (slice LogicalInterfaces) Less(i, j int) bool {
+    return slice[i].IfName < slice[j].IfName
+}
+
+//
+//
+func (slice LogicalInterfaces) Swap(i, j int) {
+    slice[i], slice[j] = slice[j], slice[i]
+}
+
+//
+//
+func (slice PhysicalInterfaces) Len() int {
+    return len(slice)
+}
+
+//
+//
+func (slice PhysicalInterfaces) Less(i, j int) bool {
+    return slice[i].IfName + slice[i].OuterVLAN + "." + slice[i].VLAN < slice[j].IfName + slice[j].OuterVLAN + "." + slice[j].VLAN
+}
+
+//
+//
+func (slice PhysicalInterfaces) Swap(i, j int) {
+    slice[i], slice[j] = slice[j], slice[i]
+}
+
+//
+//
+func (slice Routes) Len() int {
+    return len(slice)
+}
+
+//
+//
+// IPv4 routes sort before IPv6 routes, each family by network address and
+// then by prefix length
+func (slice Routes) Less(i, j int) bool {
+	fi := IPFamily(slice[i].IPNet.IP)
+	fj := IPFamily(slice[j].IPNet.IP)
+	
+	if fi != fj {
+		return fi < fj
+	}
+	
+	if c := bytes.Compare(slice[i].IPNet.IP.To16(), slice[j].IPNet.IP.To16()); c != 0 {
+		return c < 0
+	}
+	
+	oi, _ := slice[i].IPNet.Mask.Size()
+	oj, _ := slice[j].IPNet.Mask.Size()
+
+	return oi < oj
+}
+
+//
+//
+func (slice Routes) Swap(i, j int) {
+    slice[i], slice[j] = slice[j], slice[i]
+}
+
+//
+// IPFamily returns 4 or 6; unparsed (nil) addresses count as 0 and sort first
+func IPFamily(ip net.IP) (int) {
+	if ip == nil {
+		return 0
+	} else if ip.To4() != nil {
+		return 4
+	}
+	
+	return 6
+}
+
+//
+//
+func IPFamilyBits(family int) (int) {
+	if family == 6 {
+		return 8 * net.IPv6len
+	}
+	
+	return 8 * net.IPv4len
+}
+
+//
+//
+func DefaultRoute(family int) (string) {
+	if family == 6 {
+		return "::/0"
+	}
+	
+	return "0.0.0.0/0"
+}
\ No newline at end of file
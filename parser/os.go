@@ -0,0 +1,165 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VersionInfo describes the installed Check Point software. Build is always
+// the Check Point build printed by fw ver (and by show software-version on
+// Gaia Embedded); the build of the Gaia OS itself is OsBuild. FwVer and
+// Release keep the raw output of fw ver and /etc/cp-release.
+type VersionInfo struct {
+	Product		string				// e.g. "Check Point Gaia"
+	Version		string				// e.g. "R80.40"
+	Major			int
+	Minor			int
+	Build			int					// e.g. 294 from "R80.40 - Build 294"
+	OsBuild		int					// 'OS build' of show version all, 0 when not in CLISH
+	Kernel			string
+	Take			int					// Jumbo Hotfix take, 0 when none
+	Model			string				// appliance model, empty on open servers
+	Edition		string				// "32-bit" or "64-bit"
+
+	FwVer			string
+	Release		string
+}
+
+var (
+	reCpRelease		= regexp.MustCompile(`R(\d+)(?:\.(\d+))?(?:\.\d+)?`)
+	reFwVerBuild		= regexp.MustCompile(`Build (\d+)`)
+	reJumboTake		= regexp.MustCompile(`(?i)JUMBO.*Take:\s*(\d+)`)
+	reSmbModel		= regexp.MustCompile(`Check Point's (.+?) Appliance`)
+)
+
+//
+// ParseJumboTake returns the highest Jumbo Hotfix take listed by cpinfo -y
+func ParseJumboTake(cpinfo string) (take int) {
+	for _, v := range strings.Split(cpinfo, "\n") {
+		if m := reJumboTake.FindStringSubmatch(v); m != nil {
+			if t, _ := strconv.Atoi(m[1]); t > take {
+				take = t
+			}
+		}
+	}
+	
+	return take
+}
+
+//
+// ParseShowVersionAll reads the output of clish 'show version all'
+func ParseShowVersionAll(info *VersionInfo, result string) {
+	for _, v := range strings.Split(result, "\n") {
+		v = strings.TrimSpace(v)
+		
+		if strings.HasPrefix(v, "Product version ") {
+			ParseCpRelease(info, strings.TrimPrefix(v, "Product version "))
+		} else if strings.HasPrefix(v, "OS build ") {
+			info.OsBuild, _ = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(v, "OS build ")))
+		} else if strings.HasPrefix(v, "OS kernel version ") {
+			info.Kernel = strings.TrimSpace(strings.TrimPrefix(v, "OS kernel version "))
+		} else if strings.HasPrefix(v, "OS edition ") {
+			info.Edition = strings.TrimSpace(strings.TrimPrefix(v, "OS edition "))
+		}
+	}
+}
+
+//
+// ParseCpRelease splits "Check Point Gaia R80.40" into product and version
+func ParseCpRelease(info *VersionInfo, release string) {
+	release = strings.TrimSpace(strings.Split(release, "\n")[0])
+	
+	loc := reCpRelease.FindStringSubmatchIndex(release)
+	if loc == nil {
+		return
+	}
+	
+	if info.Product == "" {
+		info.Product = strings.TrimRight(release[:loc[0]], " (")
+	}
+	
+	if info.Version == "" {
+		m := reCpRelease.FindStringSubmatch(release)
+		
+		info.Version		= m[0]
+		info.Major, _		= strconv.Atoi(m[1])
+		info.Minor, _		= strconv.Atoi(m[2])
+	}
+}
+
+//
+// ParseFwVer reads "This is Check Point's software version R80.40 - Build 294"
+func ParseFwVer(info *VersionInfo, fwver string) {
+	if info.Version == "" {
+		if i := strings.Index(fwver, "version "); i >= 0 {
+			fwver = fwver[i:]
+		}
+		
+		if m := reCpRelease.FindStringSubmatch(fwver); m != nil {
+			info.Version		= m[0]
+			info.Major, _		= strconv.Atoi(m[1])
+			info.Minor, _		= strconv.Atoi(m[2])
+		}
+	}
+	
+	if b := reFwVerBuild.FindStringSubmatch(fwver); b != nil && info.Build == 0 {
+		info.Build, _ = strconv.Atoi(b[1])
+	}
+}
+
+//
+// ParseCpstatOs takes the appliance model from 'cpstat os'
+func ParseCpstatOs(info *VersionInfo, result string) {
+	for _, v := range strings.Split(result, "\n") {
+		f := strings.SplitN(v, ":", 2)
+		
+		if len(f) == 2 && strings.TrimSpace(f[0]) == "Appliance Name" {
+			model := strings.TrimSpace(f[1])
+			
+			if model != "" && !strings.EqualFold(model, "N/A") {
+				info.Model = model
+			}
+		}
+	}
+}
+
+//
+// ParseSoftwareVersion reads Gaia Embedded 'show software-version', e.g.
+// "This is Check Point's 1590 Appliance R80.20.40 - Build 992002123"
+func ParseSoftwareVersion(info *VersionInfo, result string) {
+	for _, v := range strings.Split(result, "\n") {
+		m := reSmbModel.FindStringSubmatch(v)
+		if m == nil {
+			continue
+		}
+		
+		info.Product	= "Check Point Gaia Embedded"
+		info.Model		= m[1]
+		
+		ParseFwVer(info, v[len(m[0]):])
+		
+		break
+	}
+}
@@ -0,0 +1,251 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files from the current parser output")
+
+// goldenTests run a parser on testdata/<platform>/<name>.txt and compare its
+// output with testdata/<platform>/<name>.golden
+var goldenTests = []struct {
+	input	string
+	parse	func(text string) (result interface{}, warnings Warnings)
+}{
+	{"gaia/ip-addr.txt", func(text string) (interface{}, Warnings) { return ParseIPAddr(text) }},
+	{"gaia/ip-route.txt", func(text string) (interface{}, Warnings) { return ParseIPRoute(text) }},
+	{"ipso/ifconfig.txt", func(text string) (interface{}, Warnings) {
+		logical, physical, warnings := ParseIPSOIfconfig(text)
+		return struct{ Logical LogicalInterfaces; Physical PhysicalInterfaces }{logical, physical}, warnings
+	}},
+	{"ipso/netstat-rn.txt", func(text string) (interface{}, Warnings) { return ParseIPSONetstatRoutes(text, 4) }},
+	{"ipso/netstat-rn-inet6.txt", func(text string) (interface{}, Warnings) { return ParseIPSONetstatRoutes(text, 6) }},
+	{"gaia/cphaprob-stat.txt", func(text string) (interface{}, Warnings) { return ParseCphaprobStat(text) }},
+	{"splat/cphaprob-stat.txt", func(text string) (interface{}, Warnings) { return ParseCphaprobStat(text) }},
+	{"gaia/cphaprob-list.txt", func(text string) (interface{}, Warnings) {
+		cpha := &CphaData{}
+		return cpha, ParseCphaprobList(cpha, text)
+	}},
+	{"gaia/cphaprob-a-if.txt", func(text string) (interface{}, Warnings) {
+		cpha := &CphaData{}
+		return cpha, ParseCphaprobIf(cpha, text)
+	}},
+	{"splat/cphaprob-a-if.txt", func(text string) (interface{}, Warnings) {
+		cpha := &CphaData{}
+		return cpha, ParseCphaprobIf(cpha, text)
+	}},
+	{"gaia/cphaprob-syncstat.txt", func(text string) (interface{}, Warnings) {
+		cpha := &CphaData{}
+		ParseCphaprobSyncstat(cpha, text)
+		return cpha, nil
+	}},
+	{"scalable/asg-monitor.txt", func(text string) (interface{}, Warnings) { return ParseAsgMonitor(text) }},
+	{"xos/show-vap-group.txt", func(text string) (interface{}, Warnings) { return ParseVAPGroups(text) }},
+	{"xos/show-version.txt", func(text string) (interface{}, Warnings) {
+		product, version, found := ParseXOSVersion(text)
+		return struct{ Product, Version string; Found bool }{product, version, found}, nil
+	}},
+	{"gaia/ip-neigh.txt", func(text string) (interface{}, Warnings) { return ParseIPNeigh(text) }},
+	{"ipso/arp-an.txt", func(text string) (interface{}, Warnings) { return ParseArpAn(text) }},
+	{"gaia/local-arp.txt", func(text string) (interface{}, Warnings) { return ParseLocalArp(text) }},
+	{"gaia/fw-ctl-arp.txt", func(text string) (interface{}, Warnings) { return ParseFwCtlArp(text), nil }},
+	{"xos/show-arp.txt", func(text string) (interface{}, Warnings) { return ParseXOSArp(text), nil }},
+	{"gaia/proc-bonding.txt", func(text string) (interface{}, Warnings) { return ParseProcBonding(text), nil }},
+	{"gaia/bridge-members.txt", func(text string) (interface{}, Warnings) { return ParseBridgeMembers(text), nil }},
+	{"gaia/proc-vlan-config.txt", func(text string) (interface{}, Warnings) { return ParseProcVlanConfig(text) }},
+	{"gaia/show-configuration-interface.txt", func(text string) (interface{}, Warnings) { return ParseClishVlans(text) }},
+	{"gaia/show-route.txt", func(text string) (interface{}, Warnings) { return ParseShowRoute(text) }},
+	{"gaia/show-ospf-neighbors.txt", func(text string) (interface{}, Warnings) { return ParseOSPFNeighbors(text) }},
+	{"gaia/show-ospf-interfaces.txt", func(text string) (interface{}, Warnings) { return ParseOSPFInterfaces(text) }},
+	{"gaia/show-bgp-peers.txt", func(text string) (interface{}, Warnings) { return ParseBGPPeers(text) }},
+	{"gaia/show-configuration-static-route.txt", func(text string) (interface{}, Warnings) { return ParseStaticRouteConfig(text) }},
+	{"gaia/cpstat-mg.txt", func(text string) (interface{}, Warnings) {
+		info := &ManagementInfo{}
+		ParseCpstatMg(info, text)
+		return info, nil
+	}},
+	{"gaia/cpwd-admin-list.txt", func(text string) (interface{}, Warnings) { return ParseCpwdAdminList(text) }},
+	{"gaia/mdsstat.txt", func(text string) (interface{}, Warnings) { return ParseMdsstat(text) }},
+	{"gaia/vsx-stat-v.txt", func(text string) (interface{}, Warnings) { return ParseVsxStat(text) }},
+	{"scalable/g-all.txt", func(text string) (interface{}, Warnings) { return ParseGAll(text) }},
+	{"gaia/ip-link.txt", func(text string) (interface{}, Warnings) { return ParseIPLink(text) }},
+	{"gaia/ethtool.txt", func(text string) (interface{}, Warnings) {
+		physical, _ := ParseIPLink(testdata("gaia/ip-link.txt"))
+		ParseEthtool(physical, text)
+		return physical, nil
+	}},
+	{"ipso/netstat-in.txt", func(text string) (interface{}, Warnings) { return ParseIPSOPhysical(testdata("ipso/ifconfig.txt"), text), nil }},
+	{"xos/show-interface.txt", func(text string) (interface{}, Warnings) { return ParseXOSInterfaces(text), nil }},
+	{"gaia/show-version-all.txt", func(text string) (interface{}, Warnings) {
+		info := &VersionInfo{}
+		ParseShowVersionAll(info, text)
+		return info, nil
+	}},
+	{"gaia/cp-release.txt", func(text string) (interface{}, Warnings) {
+		info := &VersionInfo{}
+		ParseCpRelease(info, text)
+		return info, nil
+	}},
+	{"splat/cp-release.txt", func(text string) (interface{}, Warnings) {
+		info := &VersionInfo{}
+		ParseCpRelease(info, text)
+		return info, nil
+	}},
+	{"gaia/fw-ver.txt", func(text string) (interface{}, Warnings) {
+		info := &VersionInfo{}
+		ParseFwVer(info, text)
+		return info, nil
+	}},
+	{"gaia/cpstat-os.txt", func(text string) (interface{}, Warnings) {
+		info := &VersionInfo{}
+		ParseCpstatOs(info, text)
+		return info, nil
+	}},
+	{"gaia/cpinfo-y-all.txt", func(text string) (interface{}, Warnings) { return ParseJumboTake(text), nil }},
+	{"embedded/show-software-version.txt", func(text string) (interface{}, Warnings) {
+		info := &VersionInfo{}
+		ParseSoftwareVersion(info, text)
+		return info, nil
+	}},
+}
+
+//
+// testdata returns the capture a parser needs next to its golden input
+func testdata(name string) (string) {
+	text, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		panic(err)
+	}
+
+	return string(text)
+}
+
+//
+//
+func TestGolden(t *testing.T) {
+	for _, tt := range goldenTests {
+		t.Run(tt.input, func(t *testing.T) {
+			text, err := os.ReadFile(filepath.Join("testdata", tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, warnings := tt.parse(string(text))
+			got    := dump(result, warnings)
+			golden := filepath.Join("testdata", strings.TrimSuffix(tt.input, ".txt") + ".golden")
+
+			if *update {
+				if err = os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != string(want) {
+				t.Errorf("output differs from %s (go test -update rewrites it)\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+//
+// dump renders a parser result one item per line: slice elements, struct
+// fields (with slice fields expanded below them) and map entries in key order,
+// followed by the warnings
+func dump(result interface{}, warnings Warnings) (string) {
+	var b strings.Builder
+
+	v := reflect.ValueOf(result)
+
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+		case reflect.Slice:
+			dumpSlice(&b, "", v)
+
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				if f := v.Field(i); f.Kind() == reflect.Slice {
+					fmt.Fprintf(&b, "%s:\n", v.Type().Field(i).Name)
+					dumpSlice(&b, "\t", f)
+				} else {
+					fmt.Fprintf(&b, "%s: %+v\n", v.Type().Field(i).Name, f.Interface())
+				}
+			}
+
+		case reflect.Map:
+			var keys []string
+
+			for _, k := range v.MapKeys() {
+				keys = append(keys, fmt.Sprint(k.Interface()))
+			}
+
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				fmt.Fprintf(&b, "%s: %+v\n", k, v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key())).Interface())
+			}
+
+		default:
+			fmt.Fprintf(&b, "%+v\n", result)
+	}
+
+	if len(warnings) > 0 {
+		b.WriteString("-- warnings\n")
+
+		for _, w := range warnings {
+			b.WriteString(w.String() + "\n")
+		}
+	}
+
+	// empty values would leave trailing blanks
+	lines := strings.Split(b.String(), "\n")
+
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+//
+//
+func dumpSlice(b *strings.Builder, indent string, v reflect.Value) {
+	for i := 0; i < v.Len(); i++ {
+		fmt.Fprintf(b, "%s%+v\n", indent, v.Index(i).Interface())
+	}
+}
@@ -0,0 +1,312 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+//
+// ParseIPLink reads 'ip -o -s link', one interface per line with the original
+// line breaks shown as '\'. VLAN devices keep their own name, e.g. 'eth1.100';
+// VLANs.Resolve maps them to the device and tags.
+//
+//	2: eth0: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc mq state UP qlen 1000\    link/ether 00:1c:7f:00:00:01 brd ff:ff:ff:ff:ff:ff\    RX: bytes  packets  errors  dropped overrun mcast   \    123 4 0 0 0 0 \    TX: bytes  packets  errors  dropped carrier collsns \    567 8 0 0 0 0
+func ParseIPLink(result string) (physical PhysicalInterfaces, warnings Warnings) {
+	for l, v := range strings.Split(result, "\n") {
+		parts := strings.Split(v, "\\")
+		f     := strings.Fields(parts[0])
+
+		if len(f) == 0 || !strings.HasSuffix(f[0], ":") {
+			continue
+		}
+
+		if len(f) < 3 {
+			warnings.Add("ParseIPLink", l, v, "interface without flags")
+			continue
+		}
+
+		name := strings.TrimSuffix(f[1], ":")
+
+		if name == "lo" {
+			continue
+		}
+
+		var ni NetworkPhysicalInterface
+
+		// stacked devices are shown as 'eth1.100@eth1'
+		ni.IfName = strings.SplitN(name, "@", 2)[0]
+
+		flags := strings.Split(strings.Trim(f[2], "<>"), ",")
+
+		ni.AdminUp = containsString(flags, "UP")
+		ni.OperUp  = containsString(flags, "LOWER_UP")
+
+		for i := 3; i + 1 < len(f); i++ {
+			switch f[i] {
+				case "mtu":
+					ni.MTU, _ = strconv.Atoi(f[i + 1])
+				case "state":
+					// 'state UNKNOWN' is reported by tunnels and bonds without carrier detection
+					if f[i + 1] == "UP" {
+						ni.OperUp = true
+					} else if f[i + 1] == "DOWN" {
+						ni.OperUp = false
+					}
+			}
+		}
+
+		for k := 1; k < len(parts); k++ {
+			p := strings.Fields(parts[k])
+
+			if len(p) >= 2 && strings.HasPrefix(p[0], "link/") {
+				ni.MAC = p[1]
+			} else if len(p) >= 1 && (p[0] == "RX:" || p[0] == "TX:") && k + 1 < len(parts) {
+				c := strings.Fields(parts[k + 1])
+
+				if len(c) >= 4 {
+					e, _ := strconv.ParseUint(c[2], 10, 64)
+					d, _ := strconv.ParseUint(c[3], 10, 64)
+
+					if p[0] == "RX:" {
+						ni.RxErrors, ni.RxDropped = e, d
+					} else {
+						ni.TxErrors, ni.TxDropped = e, d
+					}
+				}
+			}
+		}
+
+		physical = append(physical, ni)
+	}
+
+	return physical, warnings
+}
+
+//
+// ParseEthtool adds speed, duplex and driver from 'ethtool' and 'ethtool -i',
+// each interface preceded by a '== <name>' line
+func ParseEthtool(physical PhysicalInterfaces, result string) {
+	var cur *NetworkPhysicalInterface
+
+	for _, v := range strings.Split(result, "\n") {
+		v = strings.TrimSpace(v)
+
+		if strings.HasPrefix(v, "== ") {
+			cur  = nil
+			name := strings.TrimPrefix(v, "== ")
+
+			for i := range physical {
+				if physical[i].IfName == name && physical[i].VLAN == "" {
+					cur = &physical[i]
+				}
+			}
+
+			continue
+		}
+
+		if cur == nil {
+			continue
+		}
+
+		f := strings.SplitN(v, ":", 2)
+
+		if len(f) != 2 {
+			continue
+		}
+
+		value := strings.TrimSpace(f[1])
+
+		switch f[0] {
+			case "Speed":
+				cur.Speed, _ = strconv.Atoi(strings.TrimSuffix(value, "Mb/s"))
+			case "Duplex":
+				if value != "Unknown!" {
+					cur.Duplex = strings.ToLower(value)
+				}
+			case "driver":
+				cur.Driver = value
+		}
+	}
+}
+
+//
+// ParseIPSOPhysical reads the physical entries of IPSO 'ifconfig -a'
+//
+//	eth-s1p1: flags=4863<UP,BROADCAST,MULTICAST,LINK,AUTOLINK> mtu 1500
+//		ether 0:a0:8e:12:34:56 speed 1000M full duplex
+//
+// and the Ierrs / Oerrs columns of 'netstat -in'
+func ParseIPSOPhysical(ifconfig string, netstat string) (physical PhysicalInterfaces) {
+	var cur *NetworkPhysicalInterface
+
+	for _, v := range strings.Split(ifconfig, "\n") {
+		if v == "" {
+			continue
+		}
+
+		f := strings.Fields(v)
+
+		if v[0] != '\t' && v[0] != ' ' {
+			cur = nil
+
+			// logical interfaces carry an 'lname' and are read by ParseIPSOIfconfig
+			if len(f) < 2 || f[1] == "lname" || !strings.HasSuffix(f[0], ":") || strings.HasPrefix(f[0], "loop") {
+				continue
+			}
+
+			var ni NetworkPhysicalInterface
+			ni.IfName = strings.TrimSuffix(f[0], ":")
+
+			for i, vv := range f {
+				if strings.HasPrefix(vv, "flags=") {
+					flags := vv[strings.Index(vv, "<") + 1:]
+					flags  = strings.TrimSuffix(flags, ">")
+
+					ni.AdminUp = containsString(strings.Split(flags, ","), "UP")
+					ni.OperUp  = containsString(strings.Split(flags, ","), "LINK")
+				} else if vv == "mtu" && i + 1 < len(f) {
+					ni.MTU, _ = strconv.Atoi(f[i + 1])
+				}
+			}
+
+			physical = append(physical, ni)
+			cur = &physical[len(physical) - 1]
+
+			continue
+		}
+
+		if cur == nil {
+			continue
+		}
+
+		for i, vv := range f {
+			if i + 1 >= len(f) {
+				break
+			}
+
+			switch vv {
+				case "ether":
+					cur.MAC = f[i + 1]
+				case "speed":
+					cur.Speed = parseSpeed(f[i + 1])
+			}
+		}
+
+		if len(f) >= 2 && f[len(f) - 1] == "duplex" {
+			cur.Duplex = f[len(f) - 2]
+		}
+	}
+
+	// Name Mtu Network Address Ipkts Ierrs Opkts Oerrs Coll
+	for _, v := range strings.Split(netstat, "\n") {
+		f := strings.Fields(v)
+
+		if len(f) < 8 || !strings.HasPrefix(f[2], "<Link") {
+			continue
+		}
+
+		for i := range physical {
+			if physical[i].IfName == f[0] {
+				n := len(f)
+
+				physical[i].RxErrors, _ = strconv.ParseUint(f[n - 4], 10, 64)
+				physical[i].TxErrors, _ = strconv.ParseUint(f[n - 2], 10, 64)
+			}
+		}
+	}
+
+	return physical
+}
+
+//
+// ParseXOSInterfaces reads CrossBeam XOS 'show interface', a block of
+// 'Key : value' lines per interface
+func ParseXOSInterfaces(result string) (physical PhysicalInterfaces) {
+	var cur *NetworkPhysicalInterface
+
+	for _, v := range strings.Split(result, "\n") {
+		f := strings.SplitN(v, ":", 2)
+
+		if len(f) != 2 {
+			continue
+		}
+
+		key   := strings.ToLower(strings.TrimSpace(f[0]))
+		value := strings.TrimSpace(f[1])
+
+		if key == "interface" {
+			physical = append(physical, NetworkPhysicalInterface{IfName: value})
+			cur = &physical[len(physical) - 1]
+
+			continue
+		}
+
+		if cur == nil {
+			continue
+		}
+
+		switch key {
+			case "admin state", "admin status":
+				cur.AdminUp = value == "up" || value == "enabled"
+			case "link state", "link status", "oper state", "oper status":
+				cur.OperUp = value == "up"
+			case "mac address":
+				cur.MAC = value
+			case "mtu":
+				cur.MTU, _ = strconv.Atoi(value)
+			case "speed":
+				cur.Speed = parseSpeed(value)
+			case "duplex":
+				cur.Duplex = strings.ToLower(value)
+			case "input errors", "rx errors":
+				cur.RxErrors, _ = strconv.ParseUint(value, 10, 64)
+			case "output errors", "tx errors":
+				cur.TxErrors, _ = strconv.ParseUint(value, 10, 64)
+			case "input drops", "rx drops", "rx dropped":
+				cur.RxDropped, _ = strconv.ParseUint(value, 10, 64)
+			case "output drops", "tx drops", "tx dropped":
+				cur.TxDropped, _ = strconv.ParseUint(value, 10, 64)
+		}
+	}
+
+	return physical
+}
+
+//
+// parseSpeed turns '1000M', '10G', '100Mbps' or '1000' into Mb/s
+func parseSpeed(s string) (int) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimSuffix(s, "BPS")
+	s = strings.TrimSuffix(s, "B/S")
+
+	mult := 1
+
+	if strings.HasSuffix(s, "G") {
+		mult = 1000
+	}
+
+	n, _ := strconv.Atoi(strings.TrimRight(s, "MG"))
+
+	return n * mult
+}
@@ -0,0 +1,384 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ClishRoute is one route of CLISH 'show route' with the protocol that
+// installed it
+type ClishRoute struct {
+	Net			string
+	Family		int
+	Code		string			// raw route code, e.g. "O IA", "S", "B"
+	Protocol	string			// "connected", "static", "ospf", "bgp", "rip", "kernel", "aggregate" or "unknown"
+	Gateways	[]string		// more than one for ECMP
+	IfNames		[]string
+	Cost		string
+	Inactive	bool
+}
+
+type ClishRoutes []ClishRoute
+
+//
+//
+type OSPFNeighbor struct {
+	RouterID	string
+	Priority	int
+	State		string			// e.g. "FULL", "FULL/DR", "2WAY"
+	Dead		int				// seconds
+	Address		string
+	Interface	string			// local interface address
+	Errors		int
+}
+
+//
+//
+type OSPFInterface struct {
+	IfName		string
+	IP			string
+	Area		string
+	State		string			// e.g. "DR", "BDR", "DROTHER", "P2P"
+	Neighbors	int
+	Cost		int
+}
+
+//
+//
+type BGPPeer struct {
+	PeerID		string
+	AS			string			// string as 4-byte AS may be written as "1.10"
+	Routes		int
+	Active		int
+	State		string			// e.g. "Established", "Active", "Idle"
+	InUpdates	int
+	OutUpdates	int
+	Uptime		string
+}
+
+// StaticRoute is one next hop of a configured static route. A route with
+// several gateways yields one StaticRoute per gateway.
+type StaticRoute struct {
+	Net			string			// "default" is kept as 0.0.0.0/0 or ::/0
+	Family		int
+	Type		string			// "gateway", "blackhole" or "reject"
+	Gateway		string			// gateway address, for Type "gateway"
+	IfName		string			// set for 'nexthop gateway logical <if>'
+	Priority	int
+	Enabled		bool
+	Comment		string
+}
+
+type StaticRoutes []StaticRoute
+
+//
+// ParseShowRoute reads CLISH 'show route' / 'show ipv6 route'
+//
+//	S         0.0.0.0/0           via 10.0.0.1, eth0, cost 0, age 12345
+//	                              via 10.0.0.4, eth2, cost 0, age 12345
+//	C         10.0.0.0/24         is directly connected, eth0
+//	O IA      172.16.0.0/16       via 10.0.0.2, eth1, cost 20, age 100
+func ParseShowRoute(result string) (routes ClishRoutes, warnings Warnings) {
+	for l, v := range strings.Split(result, "\n") {
+		f := strings.Fields(v)
+
+		if len(f) == 0 {
+			continue
+		}
+
+		// ECMP continuation of the previous route
+		if f[0] == "via" {
+			if len(routes) > 0 {
+				parseShowRouteNexthop(&routes[len(routes) - 1], strings.Join(f, " "))
+			} else {
+				warnings.Add("ParseShowRoute", l, v, "next hop without route")
+			}
+
+			continue
+		}
+
+		k := -1
+
+		for i := range f {
+			if _, _, err := net.ParseCIDR(f[i]); err == nil {
+				k = i
+				break
+			}
+		}
+
+		// the route code is one or two words in front of the prefix
+		if k < 1 || k > 3 {
+			continue
+		}
+
+		ip, _, _ := net.ParseCIDR(f[k])
+
+		r := ClishRoute{Net: f[k], Family: IPFamily(ip), Code: strings.Join(f[:k], " ")}
+
+		switch f[0][0] {
+			case 'C':
+				r.Protocol = "connected"
+			case 'S':
+				r.Protocol = "static"
+			case 'O':
+				r.Protocol = "ospf"
+			case 'B':
+				r.Protocol = "bgp"
+			case 'R':
+				r.Protocol = "rip"
+			case 'K':
+				r.Protocol = "kernel"
+			case 'A':
+				r.Protocol = "aggregate"
+			default:
+				r.Protocol = "unknown"
+		}
+
+		for _, c := range f[:k] {
+			if c == "i" {
+				r.Inactive = true
+			}
+		}
+
+		parseShowRouteNexthop(&r, strings.Join(f[k + 1:], " "))
+
+		routes = append(routes, r)
+	}
+
+	return routes, warnings
+}
+
+//
+// parseShowRouteNexthop reads 'via 10.0.0.1, eth0, cost 0, age 12345' or
+// 'is directly connected, eth0'
+func parseShowRouteNexthop(r *ClishRoute, s string) {
+	p := strings.Split(s, ",")
+
+	for i := range p {
+		p[i] = strings.TrimSpace(p[i])
+	}
+
+	if strings.HasPrefix(p[0], "via ") {
+		r.Gateways = append(r.Gateways, strings.TrimPrefix(p[0], "via "))
+	}
+
+	if len(p) > 1 && !strings.Contains(p[1], " ") {
+		r.IfNames = append(r.IfNames, p[1])
+	}
+
+	for _, q := range p {
+		if strings.HasPrefix(q, "cost ") {
+			r.Cost = strings.TrimPrefix(q, "cost ")
+		}
+	}
+}
+
+//
+// ParseOSPFNeighbors reads
+//
+//	Neighbor ID     Pri  State          Dead  Address         Interface       Errors
+//	1.1.1.1         1    FULL/DR        35    10.0.0.2        10.0.0.1        0
+func ParseOSPFNeighbors(result string) (neighbors []OSPFNeighbor, warnings Warnings) {
+	for l, v := range strings.Split(result, "\n") {
+		f := strings.Fields(v)
+
+		if len(f) == 0 || net.ParseIP(f[0]) == nil {
+			continue
+		}
+
+		if len(f) < 6 {
+			warnings.Add("ParseOSPFNeighbors", l, v, "neighbor with too few fields")
+			continue
+		}
+
+		var n OSPFNeighbor
+		n.RouterID		= f[0]
+		n.Priority, _	= strconv.Atoi(f[1])
+		n.State			= f[2]
+		n.Dead, _		= strconv.Atoi(f[3])
+		n.Address		= f[4]
+		n.Interface		= f[5]
+
+		if len(f) > 6 {
+			n.Errors, _ = strconv.Atoi(f[6])
+		}
+
+		neighbors = append(neighbors, n)
+	}
+
+	return neighbors, warnings
+}
+
+//
+// ParseOSPFInterfaces reads
+//
+//	Name            IP Address      Area ID         State   NC      Cost
+//	eth1            10.0.0.1/24     0.0.0.0         DR      1       10
+func ParseOSPFInterfaces(result string) (interfaces []OSPFInterface, warnings Warnings) {
+	for l, v := range strings.Split(result, "\n") {
+		f := strings.Fields(v)
+
+		if len(f) < 2 || net.ParseIP(strings.SplitN(f[1], "/", 2)[0]) == nil {
+			continue
+		}
+
+		if len(f) < 6 || net.ParseIP(f[2]) == nil {
+			warnings.Add("ParseOSPFInterfaces", l, v, "interface without area, state, neighbors or cost")
+			continue
+		}
+
+		var i OSPFInterface
+		i.IfName		= f[0]
+		i.IP			= f[1]
+		i.Area			= f[2]
+		i.State			= f[3]
+		i.Neighbors, _	= strconv.Atoi(f[4])
+		i.Cost, _		= strconv.Atoi(f[5])
+
+		interfaces = append(interfaces, i)
+	}
+
+	return interfaces, warnings
+}
+
+//
+// ParseBGPPeers reads
+//
+//	PeerID           AS     Routes  ActRts  State             InUpds  OutUpds  Uptime
+//	10.0.0.5         65001  12      10      Established       20      5        01:02:03
+func ParseBGPPeers(result string) (peers []BGPPeer, warnings Warnings) {
+	for l, v := range strings.Split(result, "\n") {
+		f := strings.Fields(v)
+
+		if len(f) == 0 || net.ParseIP(f[0]) == nil {
+			continue
+		}
+
+		if len(f) < 5 {
+			warnings.Add("ParseBGPPeers", l, v, "peer with too few fields")
+			continue
+		}
+
+		var p BGPPeer
+		p.PeerID		= f[0]
+		p.AS			= f[1]
+		p.Routes, _		= strconv.Atoi(f[2])
+		p.Active, _		= strconv.Atoi(f[3])
+		p.State			= f[4]
+
+		if len(f) > 6 {
+			p.InUpdates, _	= strconv.Atoi(f[5])
+			p.OutUpdates, _	= strconv.Atoi(f[6])
+		}
+
+		if len(f) > 7 {
+			p.Uptime = f[7]
+		}
+
+		peers = append(peers, p)
+	}
+
+	return peers, warnings
+}
+
+//
+// ParseStaticRouteConfig reads 'show configuration [ipv6] static-route'
+//
+//	set static-route default nexthop gateway address 10.0.0.1 on
+//	set static-route 10.1.0.0/16 nexthop gateway address 10.0.0.2 priority 2 on
+//	set static-route 10.2.0.0/16 nexthop gateway logical eth1 on
+//	set static-route 10.9.0.0/16 nexthop blackhole
+//	set static-route 10.1.0.0/16 comment "to branch"
+func ParseStaticRouteConfig(result string) (routes StaticRoutes, warnings Warnings) {
+	comments := make(map[string]string)
+
+	for l, v := range strings.Split(result, "\n") {
+		f := strings.Fields(v)
+
+		if len(f) > 1 && f[0] == "set" && f[1] == "ipv6" {
+			f = append([]string{"set"}, f[2:]...)
+		}
+
+		if len(f) < 4 || f[0] != "set" || f[1] != "static-route" {
+			continue
+		}
+
+		family := 4
+
+		if strings.Contains(v, "ipv6 static-route") {
+			family = 6
+		}
+
+		dest := f[2]
+
+		if dest == "default" {
+			dest = DefaultRoute(family)
+		}
+
+		switch f[3] {
+			case "comment":
+				comments[dest] = strings.Trim(strings.Join(f[4:], " "), "\"")
+				continue
+
+			case "nexthop":
+
+			default:
+				continue
+		}
+
+		if len(f) < 5 {
+			warnings.Add("ParseStaticRouteConfig", l, v, "nexthop without type")
+			continue
+		}
+
+		r := StaticRoute{Net: dest, Family: family, Type: f[4], Enabled: true}
+
+		for i := 5; i < len(f); i++ {
+			switch f[i] {
+				case "address":
+					if i + 1 < len(f) { r.Gateway = f[i + 1]; i++ }
+				case "logical":
+					if i + 1 < len(f) { r.IfName = f[i + 1]; i++ }
+				case "priority":
+					if i + 1 < len(f) { r.Priority, _ = strconv.Atoi(f[i + 1]); i++ }
+				case "off":
+					r.Enabled = false
+				default:
+					// IPv6 omits the 'address' keyword
+					if r.Gateway == "" && net.ParseIP(f[i]) != nil {
+						r.Gateway = f[i]
+					}
+			}
+		}
+
+		routes = append(routes, r)
+	}
+
+	for i := range routes {
+		routes[i].Comment = comments[routes[i].Net]
+	}
+
+	return routes, warnings
+}
@@ -0,0 +1,703 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Template is a compiled TextFSM template. The syntax is that of TextFSM: a
+// block of Value lines, a blank line, then states, each a name followed by
+// indented rules
+//
+//	Value Required Name (\S+)
+//	Value Filldown State (\S+)
+//	Value List Members (\S+)
+//
+//	Start
+//	  ^Bond ${Name} is ${State} -> Continue
+//	  ^\s+member ${Members}
+//	  ^end -> Record
+//
+// The Value options Filldown, Fillup, Key, List and Required, the line actions
+// Next and Continue, the record actions NoRecord, Record, Clear and Clearall,
+// state changes, the reserved End and EOF states and Error are supported.
+// Regular expressions are Go's (RE2), so Python look-arounds do not compile.
+type Template struct {
+	values		[]*templateValue
+	states		map[string][]*templateRule
+	eof			bool							// record at end of input; false if the template defines an EOF state
+}
+
+// Record is one row of Template output, keyed by Value name. The entries of a
+// List value are joined with "\n", which no single line match can contain.
+type Record map[string]string
+
+type Records []Record
+
+type templateValue struct {
+	name		string
+	regex		string
+	filldown	bool
+	fillup		bool
+	key			bool
+	list		bool
+	required	bool
+}
+
+type templateRule struct {
+	line		int
+	regex		*regexp.Regexp
+	groups		[]int							// value index of each subexpression, -1 for unnamed ones
+	next		bool							// false for Continue
+	record		string							// "", "Record", "Clear" or "Clearall"
+	state		string
+	err			string							// message of an Error action
+	isErr		bool
+}
+
+var templateValueName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+//
+// ParseTemplate compiles a TextFSM template
+func ParseTemplate(text string) (t *Template, err error) {
+	t = &Template{states: make(map[string][]*templateRule), eof: true}
+
+	lines := strings.Split(strings.Replace(text, "\r", "", -1), "\n")
+	l     := 0
+
+	// Value lines, up to the first blank line
+	for ; l < len(lines); l++ {
+		v := strings.TrimSpace(lines[l])
+
+		if strings.HasPrefix(v, "#") {
+			continue
+		}
+
+		if v == "" {
+			if len(t.values) == 0 {
+				continue
+			}
+
+			break
+		}
+
+		if err = t.parseValue(v); err != nil {
+			return nil, fmt.Errorf("template line %d: %s", l + 1, err.Error())
+		}
+	}
+
+	if len(t.values) == 0 {
+		return nil, fmt.Errorf("template has no Value lines")
+	}
+
+	var state string
+
+	for ; l < len(lines); l++ {
+		v := lines[l]
+		s := strings.TrimSpace(v)
+
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+
+		if v[0] != ' ' && v[0] != '\t' {
+			if !templateValueName.MatchString(s) {
+				return nil, fmt.Errorf("template line %d: invalid state name '%s'", l + 1, s)
+			}
+
+			if _, ok := t.states[s]; ok {
+				return nil, fmt.Errorf("template line %d: duplicate state '%s'", l + 1, s)
+			}
+
+			if s == "End" {
+				return nil, fmt.Errorf("template line %d: state 'End' is reserved", l + 1)
+			}
+
+			state = s
+			t.states[state] = nil
+
+			if state == "EOF" {
+				t.eof = false
+			}
+
+			continue
+		}
+
+		if state == "" {
+			return nil, fmt.Errorf("template line %d: rule outside a state", l + 1)
+		}
+
+		r, err := t.parseRule(s)
+		if err != nil {
+			return nil, fmt.Errorf("template line %d: %s", l + 1, err.Error())
+		}
+
+		r.line = l + 1
+
+		t.states[state] = append(t.states[state], r)
+	}
+
+	if _, ok := t.states["Start"]; !ok {
+		return nil, fmt.Errorf("template has no 'Start' state")
+	}
+
+	for name, rules := range t.states {
+		for _, r := range rules {
+			if _, ok := t.states[r.state]; r.state != "" && r.state != "End" && !ok {
+				return nil, fmt.Errorf("template line %d: state '%s' used in '%s' is not defined", r.line, r.state, name)
+			}
+		}
+	}
+
+	return t, nil
+}
+
+//
+// Values returns the Value names in the order they are declared
+func (t *Template) Values() (names []string) {
+	for _, v := range t.values {
+		names = append(names, v.name)
+	}
+
+	return names
+}
+
+//
+// Keys returns the names of the Key values in the order they are declared.
+// Together they identify a record, e.g. when comparing the records of two
+// runs; Parse itself does not use them.
+func (t *Template) Keys() (names []string) {
+	for _, v := range t.values {
+		if v.key {
+			names = append(names, v.name)
+		}
+	}
+
+	return names
+}
+
+//
+// Parse runs text through the template. An Error action stops the parse and
+// is returned as err.
+func (t *Template) Parse(text string) (records Records, err error) {
+	row   := make([]string, len(t.values))
+	lists := make([][]string, len(t.values))
+	state := "Start"
+
+	clear := func(all bool) {
+		for i, v := range t.values {
+			if all || !v.filldown {
+				row[i]   = ""
+				lists[i] = nil
+			}
+		}
+	}
+
+	record := func() {
+		empty := true
+
+		for i, v := range t.values {
+			if v.list {
+				row[i] = strings.Join(lists[i], "\n")
+			}
+
+			if row[i] != "" {
+				empty = false
+			} else if v.required {
+				clear(false)
+				return
+			}
+		}
+
+		if !empty {
+			r := make(Record)
+
+			for i, v := range t.values {
+				r[v.name] = row[i]
+			}
+
+			records = append(records, r)
+		}
+
+		clear(false)
+	}
+
+	lines := strings.Split(strings.Replace(text, "\r", "", -1), "\n")
+
+	if n := len(lines); n > 0 && lines[n - 1] == "" {
+		lines = lines[:n - 1]
+	}
+
+	for l, line := range lines {
+		for _, r := range t.states[state] {
+			m := r.regex.FindStringSubmatchIndex(line)
+
+			if m == nil {
+				continue
+			}
+
+			for g, i := range r.groups {
+				if i < 0 || m[2 * g + 2] < 0 {
+					continue
+				}
+
+				v := t.values[i]
+				s := line[m[2 * g + 2]:m[2 * g + 3]]
+
+				if v.list {
+					lists[i] = append(lists[i], s)
+				} else {
+					row[i] = s
+				}
+
+				if v.fillup {
+					for k := len(records) - 1; k >= 0 && records[k][v.name] == ""; k-- {
+						records[k][v.name] = s
+					}
+				}
+			}
+
+			if r.isErr {
+				return nil, fmt.Errorf("line %d: %s: %q", l + 1, r.err, line)
+			}
+
+			switch r.record {
+				case "Record":
+					record()
+				case "Clear":
+					clear(false)
+				case "Clearall":
+					clear(true)
+			}
+
+			if r.state != "" {
+				state = r.state
+			}
+
+			if state == "End" {
+				return records, nil
+			}
+
+			if r.next {
+				break
+			}
+		}
+	}
+
+	if t.eof {
+		record()
+	}
+
+	return records, nil
+}
+
+//
+// Decode copies records into out, a pointer to a slice of structs (or of
+// pointers to structs). A field takes the Value named in its `fsm` tag, or the
+// Value whose name equals the field name ignoring case; string, bool, integer,
+// float and []string fields are supported, the latter for List values.
+func (records Records) Decode(out interface{}) (err error) {
+	p := reflect.ValueOf(out)
+
+	if p.Kind() != reflect.Ptr || p.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("Decode: out must be a pointer to a slice")
+	}
+
+	slice := p.Elem()
+	elem  := slice.Type().Elem()
+	ptr   := elem.Kind() == reflect.Ptr
+
+	if ptr {
+		elem = elem.Elem()
+	}
+
+	if elem.Kind() != reflect.Struct {
+		return fmt.Errorf("Decode: %s is not a struct", elem.String())
+	}
+
+	for n, r := range records {
+		s := reflect.New(elem).Elem()
+
+		for i := 0; i < elem.NumField(); i++ {
+			f := elem.Field(i)
+
+			if f.PkgPath != "" {
+				continue										// unexported
+			}
+
+			value, ok := recordValue(r, f)
+			if !ok {
+				continue
+			}
+
+			if err = setField(s.Field(i), value); err != nil {
+				return fmt.Errorf("Decode: record %d: %s: %s", n, f.Name, err.Error())
+			}
+		}
+
+		if ptr {
+			slice = reflect.Append(slice, s.Addr())
+		} else {
+			slice = reflect.Append(slice, s)
+		}
+	}
+
+	p.Elem().Set(slice)
+
+	return nil
+}
+
+/******************************************************************************************************************
+* helper functions
+*
+*/
+
+//
+// parseValue reads 'Value [Option[,Option...]] Name (regex)'
+func (t *Template) parseValue(line string) (err error) {
+	i := strings.Index(line, "(")
+
+	if !strings.HasPrefix(line, "Value ") || i < 0 {
+		return fmt.Errorf("expected 'Value [options] Name (regex)'")
+	}
+
+	v := &templateValue{regex: strings.TrimSpace(line[i:])}
+	f := strings.Fields(line[:i])
+
+	switch len(f) {
+		case 2:
+			v.name = f[1]
+
+		case 3:
+			v.name = f[2]
+
+			for _, o := range strings.Split(f[1], ",") {
+				switch o {
+					case "Filldown":
+						v.filldown = true
+					case "Fillup":
+						v.fillup = true
+					case "Key":
+						v.key = true
+					case "List":
+						v.list = true
+					case "Required":
+						v.required = true
+					default:
+						return fmt.Errorf("unknown Value option '%s'", o)
+				}
+			}
+
+		default:
+			return fmt.Errorf("expected 'Value [options] Name (regex)'")
+	}
+
+	if !templateValueName.MatchString(v.name) {
+		return fmt.Errorf("invalid Value name '%s'", v.name)
+	}
+
+	if !strings.HasPrefix(v.regex, "(") || !strings.HasSuffix(v.regex, ")") {
+		return fmt.Errorf("Value '%s': regex must be enclosed in parentheses", v.name)
+	}
+
+	if _, err = regexp.Compile(v.regex); err != nil {
+		return fmt.Errorf("Value '%s': %s", v.name, err.Error())
+	}
+
+	for _, o := range t.values {
+		if o.name == v.name {
+			return fmt.Errorf("duplicate Value '%s'", v.name)
+		}
+	}
+
+	t.values = append(t.values, v)
+
+	return nil
+}
+
+//
+// parseRule reads '^regex [-> [LineOp][.RecordOp] [NewState]]' or
+// '^regex -> Error ["message"]'
+func (t *Template) parseRule(line string) (r *templateRule, err error) {
+	if !strings.HasPrefix(line, "^") {
+		return nil, fmt.Errorf("rule must start with '^'")
+	}
+
+	r = &templateRule{next: true}
+
+	regex  := line
+	action := ""
+
+	if i := strings.LastIndex(line, " -> "); i >= 0 {
+		regex  = strings.TrimSpace(line[:i])
+		action = strings.TrimSpace(line[i + 4:])
+	}
+
+	if err = r.parseAction(action); err != nil {
+		return nil, err
+	}
+
+	expanded, err := t.expand(regex)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.regex, err = regexp.Compile(expanded); err != nil {
+		return nil, err
+	}
+
+	for _, name := range r.regex.SubexpNames()[1:] {
+		i := -1
+
+		for k, v := range t.values {
+			if v.name == name {
+				i = k
+			}
+		}
+
+		r.groups = append(r.groups, i)
+	}
+
+	return r, nil
+}
+
+//
+//
+func (r *templateRule) parseAction(action string) (err error) {
+	if action == "" {
+		return nil
+	}
+
+	f := strings.Fields(action)
+
+	if strings.HasPrefix(f[0], "Error") {
+		r.isErr = true
+		r.err   = "rule raised an error"
+
+		if m := strings.TrimSpace(strings.TrimPrefix(action, "Error")); m != "" {
+			r.err = strings.Trim(m, "\"")
+		}
+
+		return nil
+	}
+
+	op := f[0]
+
+	if len(f) > 2 {
+		return fmt.Errorf("invalid action '%s'", action)
+	}
+
+	isLineOp   := func(s string) (bool) { return s == "Next" || s == "Continue" }
+	isRecordOp := func(s string) (bool) { return s == "NoRecord" || s == "Record" || s == "Clear" || s == "Clearall" }
+
+	ops := strings.SplitN(op, ".", 2)
+
+	switch {
+		case len(ops) == 2 && isLineOp(ops[0]) && isRecordOp(ops[1]):
+			r.next = ops[0] == "Next"
+			r.record = ops[1]
+		case len(ops) == 1 && isLineOp(op):
+			r.next = op == "Next"
+		case len(ops) == 1 && isRecordOp(op):
+			r.record = op
+		case len(ops) == 1 && len(f) == 1 && templateValueName.MatchString(op):
+			r.state = op					// '-> NewState' alone
+			return nil
+		default:
+			return fmt.Errorf("invalid action '%s'", op)
+	}
+
+	if r.record == "NoRecord" {
+		r.record = ""
+	}
+
+	if len(f) == 2 {
+		if !templateValueName.MatchString(f[1]) {
+			return fmt.Errorf("invalid state name '%s'", f[1])
+		}
+
+		r.state = f[1]
+	}
+
+	if !r.next && r.state != "" {
+		return fmt.Errorf("Continue cannot change state")
+	}
+
+	return nil
+}
+
+//
+// expand replaces ${Name} and $Name by the Value's regex as a named group; $$
+// is a literal '$', i.e. the end of line anchor
+func (t *Template) expand(regex string) (expanded string, err error) {
+	var b strings.Builder
+
+	for i := 0; i < len(regex); i++ {
+		c := regex[i]
+
+		if c != '$' || i + 1 >= len(regex) {
+			b.WriteByte(c)
+			continue
+		}
+
+		var name string
+
+		switch n := regex[i + 1]; {
+			case n == '$':
+				b.WriteByte('$')
+				i++
+				continue
+
+			case n == '{':
+				end := strings.IndexByte(regex[i:], '}')
+				if end < 0 {
+					return "", fmt.Errorf("unterminated '${'")
+				}
+
+				name = regex[i + 2:i + end]
+				i   += end
+
+			case n == '_' || (n >= 'A' && n <= 'Z') || (n >= 'a' && n <= 'z'):
+				k := i + 1
+
+				for k < len(regex) && (regex[k] == '_' || (regex[k] >= '0' && regex[k] <= '9') || (regex[k] >= 'A' && regex[k] <= 'Z') || (regex[k] >= 'a' && regex[k] <= 'z')) {
+					k++
+				}
+
+				name = regex[i + 1:k]
+				i    = k - 1
+
+			default:
+				b.WriteByte(c)
+				continue
+		}
+
+		var value *templateValue
+
+		for _, v := range t.values {
+			if v.name == name {
+				value = v
+			}
+		}
+
+		if value == nil {
+			return "", fmt.Errorf("unknown Value '%s'", name)
+		}
+
+		b.WriteString("(?P<" + name + ">" + value.regex[1:len(value.regex) - 1] + ")")
+	}
+
+	return b.String(), nil
+}
+
+//
+//
+func recordValue(r Record, f reflect.StructField) (value string, ok bool) {
+	if tag := f.Tag.Get("fsm"); tag != "" {
+		value, ok = r[tag]
+		return value, ok
+	}
+
+	for k, v := range r {
+		if strings.EqualFold(k, f.Name) {
+			return v, true
+		}
+	}
+
+	return "", false
+}
+
+//
+//
+func setField(f reflect.Value, value string) (err error) {
+	switch f.Kind() {
+		case reflect.String:
+			f.SetString(value)
+
+		case reflect.Bool:
+			if value == "" {
+				return nil
+			}
+
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+
+			f.SetBool(b)
+
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if value == "" {
+				return nil
+			}
+
+			n, err := strconv.ParseInt(value, 10, f.Type().Bits())
+			if err != nil {
+				return err
+			}
+
+			f.SetInt(n)
+
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if value == "" {
+				return nil
+			}
+
+			n, err := strconv.ParseUint(value, 10, f.Type().Bits())
+			if err != nil {
+				return err
+			}
+
+			f.SetUint(n)
+
+		case reflect.Float32, reflect.Float64:
+			if value == "" {
+				return nil
+			}
+
+			n, err := strconv.ParseFloat(value, f.Type().Bits())
+			if err != nil {
+				return err
+			}
+
+			f.SetFloat(n)
+
+		case reflect.Slice:
+			if f.Type().Elem().Kind() != reflect.String {
+				return fmt.Errorf("unsupported type %s", f.Type().String())
+			}
+
+			if value != "" {
+				f.Set(reflect.ValueOf(strings.Split(value, "\n")))
+			}
+
+		default:
+			return fmt.Errorf("unsupported type %s", f.Type().String())
+	}
+
+	return nil
+}
@@ -0,0 +1,350 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//
+// records is a test shorthand: each row is 'Name=value' pairs separated by
+// '|', a List value's entries separated by ','
+func records(rows ...string) (out Records) {
+	for _, row := range rows {
+		r := make(Record)
+
+		for _, kv := range strings.Split(row, "|") {
+			f := strings.SplitN(kv, "=", 2)
+			r[f[0]] = strings.Replace(f[1], ",", "\n", -1)
+		}
+
+		out = append(out, r)
+	}
+
+	return out
+}
+
+var templateTests = []struct {
+	name		string
+	template	string
+	input		string
+	want		Records
+}{
+	{
+		"implicit record at EOF",
+		"Value Name (\\S+)\n\nStart\n  ^name ${Name}\n",
+		"name a\nname b\n",
+		records("Name=b"),
+	},
+	{
+		"EOF state suppresses the implicit record",
+		"Value Name (\\S+)\n\nStart\n  ^name ${Name}\n\nEOF\n",
+		"name a\n",
+		nil,
+	},
+	{
+		"Filldown, which as in TextFSM also fills the EOF record",
+		"Value Filldown Chassis (\\S+)\nValue Slot (\\d+)\n\nStart\n  ^chassis ${Chassis}\n  ^slot ${Slot} -> Record\n",
+		"chassis c1\nslot 1\nslot 2\nchassis c2\nslot 3\n",
+		records("Chassis=c1|Slot=1", "Chassis=c1|Slot=2", "Chassis=c2|Slot=3", "Chassis=c2|Slot="),
+	},
+	{
+		"Fillup",
+		"Value Slot (\\d+)\nValue Fillup Chassis (\\S+)\n\nStart\n  ^slot ${Slot} -> Record\n  ^chassis ${Chassis}\n",
+		"slot 1\nslot 2\nchassis c1\n",
+		records("Slot=1|Chassis=c1", "Slot=2|Chassis=c1", "Slot=|Chassis=c1"),
+	},
+	{
+		"List",
+		"Value Bond (\\S+)\nValue List Members (\\S+)\n\nStart\n  ^bond ${Bond}\n  ^\\s+member ${Members}\n  ^end -> Record\n",
+		"bond b0\n  member eth1\n  member eth2\nend\nbond b1\nend\n",
+		records("Bond=b0|Members=eth1,eth2", "Bond=b1|Members="),
+	},
+	{
+		"Required",
+		"Value Required Name (\\S+)\nValue Note (\\S+)\n\nStart\n  ^name ${Name}\n  ^note ${Note}\n  ^end -> Record\n",
+		"note orphan\nend\nname a\nnote x\nend\n",
+		records("Name=a|Note=x"),
+	},
+	{
+		"Next.Record",
+		"Value A (\\S+)\n\nStart\n  ^a ${A} -> Next.Record\n  ^a -> Error\n",
+		"a 1\na 2\n",
+		records("A=1", "A=2"),
+	},
+	{
+		"Next.Clear keeps Filldown values",
+		"Value Filldown F (\\S+)\nValue A (\\S+)\n\nStart\n  ^f ${F}\n  ^a ${A}\n  ^clear -> Next.Clear\n  ^end -> Record\n\nEOF\n",
+		"f 1\na 1\nclear\nend\n",
+		records("F=1|A="),
+	},
+	{
+		"Next.Clearall",
+		"Value Filldown F (\\S+)\nValue A (\\S+)\n\nStart\n  ^f ${F}\n  ^a ${A}\n  ^clear -> Next.Clearall\n  ^end -> Record\n",
+		"f 1\na 1\nclear\na 2\nend\n",
+		records("F=|A=2"),
+	},
+	{
+		"Continue.Record",
+		"Value A (\\S+)\nValue B (\\S+)\n\nStart\n  ^${A} -> Continue.Record\n  ^\\S+ ${B}\n",
+		"x y\n",
+		records("A=x|B=", "A=|B=y"),
+	},
+	{
+		"Continue.Clear",
+		"Value A (\\S+)\nValue B (\\S+)\n\nStart\n  ^${A} -> Continue.Clear\n  ^\\S+ ${B} -> Record\n",
+		"x y\n",
+		records("A=|B=y"),
+	},
+	{
+		"Continue.Clearall",
+		"Value Filldown A (\\S+)\nValue B (\\S+)\n\nStart\n  ^${A} -> Continue.Clearall\n  ^\\S+ ${B} -> Record\n",
+		"x y\n",
+		records("A=|B=y"),
+	},
+	{
+		"Continue matches the next rule on the same line",
+		"Value A (\\S+)\nValue B (\\S+)\n\nStart\n  ^${A} -> Continue\n  ^\\S+ ${B} -> Record\n",
+		"x y\n",
+		records("A=x|B=y"),
+	},
+	{
+		"NoRecord",
+		"Value A (\\S+)\n\nStart\n  ^a ${A} -> NoRecord\n\nEOF\n",
+		"a 1\n",
+		nil,
+	},
+	{
+		"state transitions",
+		"Value Name (\\S+)\nValue Addr (\\S+)\n\nStart\n  ^interface ${Name} -> Iface\n\nIface\n  ^\\s+address ${Addr}\n  ^! -> Record Start\n",
+		"address ignored\ninterface eth0\n  address 10.0.0.1\n!\n  address ignored\n",
+		records("Name=eth0|Addr=10.0.0.1"),
+	},
+	{
+		"End stops without the EOF record",
+		"Value A (\\S+)\n\nStart\n  ^a ${A}\n  ^stop -> End\n",
+		"a 1\nstop\na 2\n",
+		nil,
+	},
+	{
+		"$$ and $Name",
+		"Value A (\\d+)\n\nStart\n  ^$A$$ -> Record\n",
+		"1\n2 x\n3\n",
+		records("A=1", "A=3"),
+	},
+}
+
+//
+//
+func TestTemplate(t *testing.T) {
+	for _, tt := range templateTests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := tmpl.Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+//
+//
+func TestTemplateError(t *testing.T) {
+	tmpl, err := ParseTemplate("Value A (\\S+)\n\nStart\n  ^ok ${A}\n  ^bad -> Error \"unexpected line\"\n  ^worse -> Error\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for input, want := range map[string]string{
+		"ok 1\nbad\n":	`line 2: unexpected line: "bad"`,
+		"worse\n":		`line 1: rule raised an error: "worse"`,
+	} {
+		if _, err = tmpl.Parse(input); err == nil || err.Error() != want {
+			t.Errorf("Parse(%q) = %v, want %s", input, err, want)
+		}
+	}
+}
+
+//
+//
+func TestTemplateValuesAndKeys(t *testing.T) {
+	tmpl, err := ParseTemplate("Value Key Name (\\S+)\nValue State (\\S+)\nValue Key,Required Vlan (\\d+)\n\nStart\n  ^${Name}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tmpl.Values(); !reflect.DeepEqual(got, []string{"Name", "State", "Vlan"}) {
+		t.Errorf("Values() = %v", got)
+	}
+
+	if got := tmpl.Keys(); !reflect.DeepEqual(got, []string{"Name", "Vlan"}) {
+		t.Errorf("Keys() = %v", got)
+	}
+}
+
+var templateParseErrors = []struct {
+	template	string
+	want		string
+}{
+	// ParseTemplate
+	{"", "template has no Value lines"},
+	{"Value A (x)\n\nstart state\n  ^x\n", "template line 3: invalid state name 'start state'"},
+	{"Value A (x)\n\nStart\n  ^x\nStart\n", "template line 5: duplicate state 'Start'"},
+	{"Value A (x)\n\nStart\n  ^x\nEnd\n", "template line 5: state 'End' is reserved"},
+	{"Value A (x)\n\n  ^x\n", "template line 3: rule outside a state"},
+	{"Value A (x)\n\nOther\n  ^x\n", "template has no 'Start' state"},
+	{"Value A (x)\n\nStart\n  ^x -> Missing\n", "template line 4: state 'Missing' used in 'Start' is not defined"},
+
+	// parseValue
+	{"Value A\n", "template line 1: expected 'Value [options] Name (regex)'"},
+	{"Val A (x)\n", "template line 1: expected 'Value [options] Name (regex)'"},
+	{"Value Filldown Required A (x)\n", "template line 1: expected 'Value [options] Name (regex)'"},
+	{"Value Sticky A (x)\n", "template line 1: unknown Value option 'Sticky'"},
+	{"Value 1A (x)\n", "template line 1: invalid Value name '1A'"},
+	{"Value A (x) y\n", "template line 1: Value 'A': regex must be enclosed in parentheses"},
+	{"Value A ([)\n", "template line 1: Value 'A': error parsing regexp: missing closing ]: `[)`"},
+	{"Value A (x)\nValue A (y)\n", "template line 2: duplicate Value 'A'"},
+
+	// parseRule
+	{"Value A (x)\n\nStart\n  x\n", "template line 4: rule must start with '^'"},
+	{"Value A (x)\n\nStart\n  ^(\n", "template line 4: error parsing regexp: missing closing ): `^(`"},
+
+	// parseAction
+	{"Value A (x)\n\nStart\n  ^x -> Next Start Again\n", "template line 4: invalid action 'Next Start Again'"},
+	{"Value A (x)\n\nStart\n  ^x -> Next.Keep\n", "template line 4: invalid action 'Next.Keep'"},
+	{"Value A (x)\n\nStart\n  ^x -> Record 1st\n", "template line 4: invalid state name '1st'"},
+	{"Value A (x)\n\nStart\n  ^x -> Continue Start\n", "template line 4: Continue cannot change state"},
+
+	// expand
+	{"Value A (x)\n\nStart\n  ^${A\n", "template line 4: unterminated '${'"},
+	{"Value A (x)\n\nStart\n  ^${B}\n", "template line 4: unknown Value 'B'"},
+	{"Value A (x)\n\nStart\n  ^$Bee\n", "template line 4: unknown Value 'Bee'"},
+}
+
+//
+//
+func TestTemplateParseErrors(t *testing.T) {
+	for _, tt := range templateParseErrors {
+		if _, err := ParseTemplate(tt.template); err == nil || err.Error() != tt.want {
+			t.Errorf("ParseTemplate(%q) = %v, want %s", tt.template, err, tt.want)
+		}
+	}
+}
+
+//
+//
+func TestRecordsDecode(t *testing.T) {
+	type row struct {
+		Name	string
+		Up		bool
+		MTU		int
+		Errors	uint64
+		Load	float64
+		Members	[]string
+		Peer	string	`fsm:"PeerAddress"`
+		hidden	string
+	}
+
+	in := records(
+		"NAME=eth0|Up=true|Mtu=1500|Errors=7|Load=0.25|Members=a,b|PeerAddress=10.0.0.1|hidden=x",
+		"NAME=eth1|Up=|Mtu=|Errors=|Load=|Members=|PeerAddress=",
+	)
+
+	want := []row{
+		{Name: "eth0", Up: true, MTU: 1500, Errors: 7, Load: 0.25, Members: []string{"a", "b"}, Peer: "10.0.0.1"},
+		{Name: "eth1"},
+	}
+
+	var got []row
+
+	if err := in.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	var ptrs []*row
+
+	if err := in.Decode(&ptrs); err != nil || len(ptrs) != 2 || ptrs[0].Name != "eth0" {
+		t.Errorf("Decode into []*row = %v, %v", ptrs, err)
+	}
+}
+
+//
+//
+func TestRecordsDecodeErrors(t *testing.T) {
+	type ints struct{ N int8 }
+	type bools struct{ B bool }
+	type uints struct{ U uint }
+	type floats struct{ F float32 }
+	type maps struct{ M map[string]string }
+	type slices struct{ S []int }
+
+	var slice []ints
+
+	for _, tt := range []struct {
+		records	Records
+		out		interface{}
+		want	string
+	}{
+		{nil, slice, "Decode: out must be a pointer to a slice"},
+		{nil, &[]string{}, "Decode: string is not a struct"},
+		{records("N=300"), &[]ints{}, `Decode: record 0: N: strconv.ParseInt: parsing "300": value out of range`},
+		{records("B=maybe"), &[]bools{}, `Decode: record 0: B: strconv.ParseBool: parsing "maybe": invalid syntax`},
+		{records("U=-1"), &[]uints{}, `Decode: record 0: U: strconv.ParseUint: parsing "-1": invalid syntax`},
+		{records("F=x"), &[]floats{}, `Decode: record 0: F: strconv.ParseFloat: parsing "x": invalid syntax`},
+		{records("M=x"), &[]maps{}, "Decode: record 0: M: unsupported type map[string]string"},
+		{records("S=1"), &[]slices{}, "Decode: record 0: S: unsupported type []int"},
+	} {
+		if err := tt.records.Decode(tt.out); err == nil || err.Error() != tt.want {
+			t.Errorf("Decode(%T) = %v, want %s", tt.out, err, tt.want)
+		}
+	}
+}
+
+//
+// FuzzTemplate compiles and runs fuzzed templates on fuzzed input, seeded
+// with templateTests
+func FuzzTemplate(f *testing.F) {
+	for _, tt := range templateTests {
+		f.Add(tt.template, tt.input)
+	}
+
+	f.Fuzz(func(t *testing.T, template string, input string) {
+		if tmpl, err := ParseTemplate(template); err == nil {
+			tmpl.Parse(input)
+		}
+	})
+}
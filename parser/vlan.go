@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NetworkVLAN is one 802.1Q sub-interface as configured on the system. For
+// QinQ the inner interface has the outer VLAN interface as its Parent.
+type NetworkVLAN struct {
+	IfName		string
+	Parent		string
+	VID			int
+}
+
+// VLANs is keyed by VLAN interface name
+type VLANs map[string]NetworkVLAN
+
+//
+// Resolve follows the parent chain of ifname down to the physical device.
+// For QinQ outer is the tag of the outer (service) VLAN, otherwise 0.
+func (vlans VLANs) Resolve(ifname string) (device string, vid int, outer int, ok bool) {
+	v, ok := vlans[ifname]
+	if !ok {
+		return ifname, 0, 0, false
+	}
+
+	vid    = v.VID
+	device = v.Parent
+
+	if p, found := vlans[v.Parent]; found {
+		outer  = p.VID
+		device = p.Parent
+
+		// anything stacked deeper than QinQ is followed to the device only
+		for i := 0; i < 8; i++ {
+			if p, found = vlans[device]; !found {
+				break
+			}
+
+			device = p.Parent
+		}
+	}
+
+	return device, vid, outer, true
+}
+
+//
+// ParseProcVlanConfig reads /proc/net/vlan/config
+//
+//	VLAN Dev name	 | VLAN ID
+//	Name-Type: VLAN_NAME_TYPE_RAW_PLUS_VID_NO_PAD
+//	eth1.100       | 100  | eth1
+func ParseProcVlanConfig(result string) (vlans VLANs, warnings Warnings) {
+	vlans = make(VLANs)
+
+	for l, v := range strings.Split(result, "\n") {
+		f := strings.Split(v, "|")
+
+		if len(f) != 3 {
+			continue
+		}
+
+		vid, err := strconv.Atoi(strings.TrimSpace(f[1]))
+		if err != nil {
+			warnings.Add("ParseProcVlanConfig", l, v, "invalid VLAN ID")
+			continue
+		}
+
+		name := strings.TrimSpace(f[0])
+
+		vlans[name] = NetworkVLAN{IfName: name, Parent: strings.TrimSpace(f[2]), VID: vid}
+	}
+
+	return vlans, warnings
+}
+
+//
+// ParseClishVlans reads the 'add interface <parent> vlan <id>' lines of
+// 'show configuration interface'. Gaia always names these <parent>.<id>.
+func ParseClishVlans(result string) (vlans VLANs, warnings Warnings) {
+	vlans = make(VLANs)
+
+	for l, v := range strings.Split(result, "\n") {
+		f := strings.Fields(v)
+
+		if len(f) < 4 || f[0] != "add" || f[1] != "interface" || f[3] != "vlan" {
+			continue
+		}
+
+		if len(f) != 5 {
+			warnings.Add("ParseClishVlans", l, v, "expected 'add interface <parent> vlan <id>'")
+			continue
+		}
+
+		vid, err := strconv.Atoi(f[4])
+		if err != nil {
+			warnings.Add("ParseClishVlans", l, v, "invalid VLAN ID")
+			continue
+		}
+
+		name := f[2] + "." + f[4]
+
+		vlans[name] = NetworkVLAN{IfName: name, Parent: f[2], VID: vid}
+	}
+
+	return vlans, warnings
+}
+
+//
+// VLANsFromNames is the fallback where the system offers no VLAN table: every
+// '.<number>' suffix is taken as a tag, so 'eth1.100.200' is QinQ on eth1
+func VLANsFromNames(logical LogicalInterfaces) (vlans VLANs) {
+	vlans = make(VLANs)
+
+	for _, i := range logical {
+		name := i.IfName
+
+		for {
+			n := strings.LastIndex(name, ".")
+			if n <= 0 {
+				break
+			}
+
+			vid, err := strconv.Atoi(name[n + 1:])
+			if err != nil {
+				break
+			}
+
+			vlans[name] = NetworkVLAN{IfName: name, Parent: name[:n], VID: vid}
+
+			name = name[:n]
+		}
+	}
+
+	return vlans
+}
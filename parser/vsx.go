@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+//
+//
+type VirtualSystem struct {
+	ID			int
+	Name		string
+	Type		string			// S = Virtual System, B = bridge mode, R = Virtual Router, W = Virtual Switch, G = VSX gateway (VS0)
+	Policy		string
+	SIC			string
+}
+
+type VirtualSystems []VirtualSystem
+
+//
+// ParseVsxStat reads 'vsx stat -v': the VS0 details, followed by the table of
+// virtual devices
+//
+//	ID   | Type & Name         | Access Control Policy | Installed at    | SIC Stat
+//	-----+---------------------+-----------------------+-----------------+---------
+//	   1 | S vs1               | vs1_policy            | 18Oct2026 10:00 | Trust
+func ParseVsxStat(result string) (vss VirtualSystems, warnings Warnings) {
+	vs0 := VirtualSystem{Type: "G"}
+	vss  = append(vss, vs0)
+
+	// go thru each line
+	for l, v := range strings.Split(result, "\n") {
+		if strings.HasPrefix(v, "Name:") {
+			vss[0].Name = strings.TrimSpace(strings.TrimPrefix(v, "Name:"))
+		} else if strings.HasPrefix(v, "Access Control Policy:") || strings.HasPrefix(v, "Security Policy:") {
+			vss[0].Policy = strings.TrimSpace(v[strings.Index(v, ":") + 1:])
+		} else if strings.HasPrefix(v, "SIC Status:") {
+			vss[0].SIC = strings.TrimSpace(strings.TrimPrefix(v, "SIC Status:"))
+		}
+
+		f := strings.Split(v, "|")
+		n := len(f)
+
+		if n < 3 {
+			continue
+		}
+
+		id, err := strconv.Atoi(strings.TrimSpace(f[0]))
+		if err != nil {
+			continue															// header or separator
+		}
+
+		tn := strings.Fields(f[1])
+
+		if len(tn) < 2 {
+			warnings.Add("ParseVsxStat", l, v, "virtual device without type and name")
+			continue
+		}
+
+		var vs VirtualSystem
+		vs.ID		= id
+		vs.Type		= tn[0]
+		vs.Name		= strings.Join(tn[1:], " ")
+		vs.Policy	= strings.TrimSpace(f[2])
+		vs.SIC		= strings.TrimSpace(f[n - 1])
+
+		vss = append(vss, vs)
+	}
+
+	return vss, warnings
+}
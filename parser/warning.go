@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package parser
+
+import (
+	"fmt"
+)
+
+// Warning is a line a parser could not make sense of. Parsers skip such lines
+// and carry on, so a Warning means missing data, not failure.
+type Warning struct {
+	Parser		string			// e.g. "ParseIPAddr"
+	Line		int				// 1-based line number in the input
+	Text		string			// the offending line
+	Message		string
+}
+
+type Warnings []Warning
+
+//
+//
+func (w Warning) String() (string) {
+	return fmt.Sprintf("%s: line %d: %s: %q", w.Parser, w.Line, w.Message, w.Text)
+}
+
+//
+// Add records message for line l (counting from 0) of the input, text being
+// the line itself
+func (warnings *Warnings) Add(parser string, l int, text string, message string) {
+	*warnings = append(*warnings, Warning{Parser: parser, Line: l + 1, Text: text, Message: message})
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"fmt"
+	"sort"
+	"github.com/mikejac/ssh.golang/parser"
+)
+
+const (
+	// one block per interface, '== <name>' followed by the interesting ethtool lines
+	ethtoolCmd = `for i in $(ls /sys/class/net); do echo "== $i"; ethtool $i 2>/dev/null | grep -E 'Speed:|Duplex:'; ethtool -i $i 2>/dev/null | grep '^driver:'; done`
+)
+
+//
+// 3300
+func (sshAction *SshAction) GetPhyInterfaceDetails() (physical PhysicalInterfaces, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetPhyInterfaceDetails(): begin\n") }
+
+	var link	string
+	var ethtool	string
+
+	switch sshAction.platform {
+		case PlatformGAiA:
+			fallthrough
+
+		case PlatformGaiaEmbedded:
+			fallthrough
+
+		case PlatformScalable:
+			fallthrough
+
+		case PlatformSplatCPSHELL:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetPhyInterfaceDetails(): PlatformGAiA, PlatformGaiaEmbedded, PlatformScalable or PlatformSplatCPSHELL\n") }
+
+			if sshAction.expertEnter() == nil {
+				link, ethtool, err = sshAction.linuxPhyInterfaces()
+
+				sshAction.expertExit()
+			}
+
+		case PlatformExpert:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetPhyInterfaceDetails(): PlatformExpert\n") }
+
+			link, ethtool, err = sshAction.linuxPhyInterfaces()
+
+		case PlatformIPSO:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetPhyInterfaceDetails(): PlatformIPSO\n") }
+
+			var ifconfig	string
+			var netstat	string
+
+			if ifconfig, err = sshAction.execute("ifconfig -a", 10); err != nil {
+				return nil, New(3300, err.Error())
+			}
+
+			if netstat, err = sshAction.execute("netstat -in", 10); err != nil {
+				return nil, New(3300, err.Error())
+			}
+
+			physical = parser.ParseIPSOPhysical(ifconfig, netstat)
+
+			sort.Sort(physical)
+
+			return physical, nil
+
+		case PlatformXBM:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetPhyInterfaceDetails(): PlatformXBM\n") }
+
+			var result string
+
+			if result, err = sshAction.execute("show interface", 10); err != nil {
+				return nil, New(3300, err.Error())
+			}
+
+			physical = parser.ParseXOSInterfaces(result)
+
+			sort.Sort(physical)
+
+			return physical, nil
+
+		default:
+			fmt.Printf("SshAction::GetPhyInterfaceDetails(): unknown platform\n")
+			return nil, New(3301, "platform unknown")
+	}
+
+	if err != nil {
+		return nil, New(3302, err.Error())
+	}
+
+	physical, warnings := parser.ParseIPLink(link)
+
+	sshAction.warn(warnings)
+
+	parser.ParseEthtool(physical, ethtool)
+
+	var names LogicalInterfaces
+
+	for _, ni := range physical {
+		names = append(names, NetworkLogicalInterface{IfName: ni.IfName})
+	}
+
+	vlans := sshAction.vlanTable(names)
+
+	for i := range physical {
+		resolveVLAN(&physical[i], physical[i].IfName, vlans)
+	}
+
+	sort.Sort(physical)
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetPhyInterfaceDetails(): end\n") }
+
+	return physical, nil
+}
+
+//
+//
+func (sshAction *SshAction) linuxPhyInterfaces() (link string, ethtool string, err error) {
+	link, err = sshAction.execute("ip -o -s link 2>&1", 10)
+	if err != nil {
+		if sshAction.verbose > 0 { fmt.Printf("SshAction::linuxPhyInterfaces(): unable to execute 'ip -o -s link 2>&1'\n") }
+		return "", "", err
+	}
+
+	// ethtool is missing on some releases; link state and counters still apply
+	if ethtool, err = sshAction.execute(ethtoolCmd, 30); err != nil {
+		ethtool = ""
+	}
+
+	return link, ethtool, nil
+}
@@ -0,0 +1,330 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	proxyDialTimeout		int = 10
+	proxyHandshakeTimeout	int = 10
+)
+
+// Dialer is anything able to open a stream connection to a host:port address.
+// *net.Dialer and golang.org/x/net/proxy dialers satisfy it.
+type Dialer interface {
+	Dial(network string, addr string) (net.Conn, error)
+}
+
+type socks5Dialer struct {
+	proxy		string
+	user		string
+	passw		string
+	resolve	bool						// socks5: resolve host names locally; socks5h leaves it to the proxy
+	timeout	time.Duration				// for the handshake, once connected to the proxy
+	forward	Dialer
+}
+
+type httpConnectDialer struct {
+	proxy		string
+	user		string
+	passw		string
+	timeout	time.Duration				// for the CONNECT exchange, once connected to the proxy
+	forward	Dialer
+}
+
+// bufferedConn hands out bytes the CONNECT response reader already pulled off
+// the wire before reading from the connection itself
+type bufferedConn struct {
+	net.Conn
+	r			*bufio.Reader
+}
+
+//
+// NewProxyDialer returns a Dialer for a proxy URL of the form
+// socks5://[user:passw@]host:port or http://[user:passw@]host:port. As with
+// curl, socks5 resolves host names locally and socks5h has the proxy do it.
+func NewProxyDialer(proxyURL string) (Dialer, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, New(1700, err.Error())
+	}
+
+	var user	string
+	var passw	string
+
+	if u.User != nil {
+		user     = u.User.Username()
+		passw, _ = u.User.Password()
+	}
+
+	forward := &net.Dialer{Timeout: time.Duration(proxyDialTimeout) * time.Second}
+	timeout := time.Duration(proxyHandshakeTimeout) * time.Second
+
+	switch u.Scheme {
+		case "socks5", "socks5h":
+			if u.Port() == "" {
+				u.Host = net.JoinHostPort(u.Hostname(), "1080")
+			}
+
+			return &socks5Dialer{proxy: u.Host, user: user, passw: passw, resolve: u.Scheme == "socks5", timeout: timeout, forward: forward}, nil
+
+		case "http":
+			if u.Port() == "" {
+				u.Host = net.JoinHostPort(u.Hostname(), "8080")
+			}
+
+			return &httpConnectDialer{proxy: u.Host, user: user, passw: passw, timeout: timeout, forward: forward}, nil
+	}
+
+	return nil, New(1701, "unsupported proxy scheme '" + u.Scheme + "'")
+}
+
+/******************************************************************************************************************
+* SOCKS5 (RFC 1928, RFC 1929)
+*
+*/
+
+//
+//
+func (d *socks5Dialer) Dial(network string, addr string) (net.Conn, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, New(1710, err.Error())
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 0xffff {
+		return nil, New(1711, "invalid port '" + portStr + "'")
+	}
+
+	if len(host) > 255 {
+		return nil, New(1712, "host name too long")
+	}
+
+	if d.resolve && net.ParseIP(host) == nil {
+		ips, err := net.LookupIP(host)
+		if err != nil || len(ips) == 0 {
+			return nil, New(1723, "unable to resolve '" + host + "'")
+		}
+
+		host = ips[0].String()
+	}
+
+	conn, err := d.forward.Dial(network, d.proxy)
+	if err != nil {
+		return nil, New(1713, err.Error())
+	}
+
+	// a proxy that accepts and then says nothing must not hang us
+	conn.SetDeadline(time.Now().Add(d.timeout))
+
+	if err = d.handshake(conn, host, port); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Time{})
+
+	return conn, nil
+}
+
+//
+//
+func (d *socks5Dialer) handshake(conn net.Conn, host string, port int) (error) {
+	buf := []byte{5, 1, 0}												// version 5, one method: no authentication
+
+	if d.user != "" {
+		buf = []byte{5, 2, 0, 2}										// ... or username/password
+	}
+
+	if _, err := conn.Write(buf); err != nil {
+		return New(1714, err.Error())
+	}
+
+	reply := make([]byte, 2)
+
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return New(1715, err.Error())
+	}
+
+	if reply[0] != 5 {
+		return New(1716, "unexpected socks version " + strconv.Itoa(int(reply[0])))
+	}
+
+	switch reply[1] {
+		case 0:
+			// no authentication required
+
+		case 2:
+			if d.user == "" {
+				return New(1717, "proxy requires authentication")
+			}
+
+			if len(d.user) > 255 || len(d.passw) > 255 {
+				return New(1718, "proxy credentials too long")
+			}
+
+			buf = []byte{1, byte(len(d.user))}
+			buf = append(buf, d.user...)
+			buf = append(buf, byte(len(d.passw)))
+			buf = append(buf, d.passw...)
+
+			if _, err := conn.Write(buf); err != nil {
+				return New(1714, err.Error())
+			}
+
+			if _, err := io.ReadFull(conn, reply); err != nil {
+				return New(1715, err.Error())
+			}
+
+			if reply[1] != 0 {
+				return New(1719, "proxy authentication failed")
+			}
+
+		default:
+			return New(1720, "no acceptable socks authentication method")
+	}
+
+	buf = []byte{5, 1, 0}												// CONNECT
+
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			buf = append(buf, 1)
+			buf = append(buf, ip4...)
+		} else {
+			buf = append(buf, 4)
+			buf = append(buf, ip...)
+		}
+	} else {
+		buf = append(buf, 3, byte(len(host)))
+		buf = append(buf, host...)
+	}
+
+	buf = append(buf, byte(port >> 8), byte(port))
+
+	if _, err := conn.Write(buf); err != nil {
+		return New(1714, err.Error())
+	}
+
+	reply = make([]byte, 4)
+
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return New(1715, err.Error())
+	}
+
+	if reply[1] != 0 {
+		return New(1721, "socks connect failed with code " + strconv.Itoa(int(reply[1])))
+	}
+
+	// skip the bound address and port
+	var skip int
+
+	switch reply[3] {
+		case 1:
+			skip = net.IPv4len + 2
+		case 4:
+			skip = net.IPv6len + 2
+		case 3:
+			l := make([]byte, 1)
+
+			if _, err := io.ReadFull(conn, l); err != nil {
+				return New(1715, err.Error())
+			}
+
+			skip = int(l[0]) + 2
+		default:
+			return New(1722, "unexpected socks address type " + strconv.Itoa(int(reply[3])))
+	}
+
+	if _, err := io.ReadFull(conn, make([]byte, skip)); err != nil {
+		return New(1715, err.Error())
+	}
+
+	return nil
+}
+
+/******************************************************************************************************************
+* HTTP CONNECT
+*
+*/
+
+//
+//
+func (d *httpConnectDialer) Dial(network string, addr string) (net.Conn, error) {
+	conn, err := d.forward.Dial(network, d.proxy)
+	if err != nil {
+		return nil, New(1730, err.Error())
+	}
+
+	conn.SetDeadline(time.Now().Add(d.timeout))
+
+	req := "CONNECT " + addr + " HTTP/1.1\r\nHost: " + addr + "\r\n"
+
+	if d.user != "" {
+		req += "Proxy-Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(d.user + ":" + d.passw)) + "\r\n"
+	}
+
+	req += "\r\n"
+
+	if _, err = conn.Write([]byte(req)); err != nil {
+		conn.Close()
+		return nil, New(1731, err.Error())
+	}
+
+	r := bufio.NewReader(conn)
+
+	resp, err := http.ReadResponse(r, &http.Request{Method: "CONNECT"})
+	if err != nil {
+		conn.Close()
+		return nil, New(1732, err.Error())
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		conn.Close()
+		return nil, New(1733, "proxy refused connect: " + resp.Status)
+	}
+
+	conn.SetDeadline(time.Time{})
+
+	if r.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: r}, nil
+	}
+
+	return conn, nil
+}
+
+//
+//
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
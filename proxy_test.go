@@ -0,0 +1,287 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+
+package sshtool
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+//
+// fakeProxy accepts one connection on a local port and hands it to serve
+func fakeProxy(t *testing.T, serve func(conn net.Conn)) (addr string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		serve(conn)
+	}()
+
+	return l.Addr().String()
+}
+
+//
+// socks5Server answers the greeting, the username/password exchange when
+// user is set, and a CONNECT request; the request's address type and
+// address go to requested. It then echoes one line.
+func socks5Server(user string, passw string, requested chan string) (func(conn net.Conn)) {
+	return func(conn net.Conn) {
+		greeting := make([]byte, 2)
+		io.ReadFull(conn, greeting)
+		io.ReadFull(conn, make([]byte, greeting[1]))
+
+		if user == "" {
+			conn.Write([]byte{5, 0})
+		} else {
+			conn.Write([]byte{5, 2})
+
+			head := make([]byte, 2)
+			io.ReadFull(conn, head)
+			u := make([]byte, head[1])
+			io.ReadFull(conn, u)
+			l := make([]byte, 1)
+			io.ReadFull(conn, l)
+			p := make([]byte, l[0])
+			io.ReadFull(conn, p)
+
+			if string(u) != user || string(p) != passw {
+				conn.Write([]byte{1, 1})
+				return
+			}
+
+			conn.Write([]byte{1, 0})
+		}
+
+		req := make([]byte, 4)
+		io.ReadFull(conn, req)
+
+		var host string
+
+		switch req[3] {
+			case 1:
+				a := make([]byte, net.IPv4len)
+				io.ReadFull(conn, a)
+				host = "ip " + net.IP(a).String()
+			case 4:
+				a := make([]byte, net.IPv6len)
+				io.ReadFull(conn, a)
+				host = "ip " + net.IP(a).String()
+			case 3:
+				l := make([]byte, 1)
+				io.ReadFull(conn, l)
+				a := make([]byte, l[0])
+				io.ReadFull(conn, a)
+				host = "name " + string(a)
+		}
+
+		io.ReadFull(conn, make([]byte, 2))
+
+		requested <- host
+
+		conn.Write([]byte{5, 0, 0, 1, 127, 0, 0, 1, 0, 22})
+
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		conn.Write([]byte(line))
+	}
+}
+
+//
+// stall accepts and then says nothing until the client gives up
+func stall(conn net.Conn) {
+	io.Copy(io.Discard, conn)
+}
+
+//
+// proxyError returns the error number of err, 0 for none
+func proxyError(err error) (int) {
+	if err == nil {
+		return 0
+	}
+
+	return errorNumber(err)
+}
+
+//
+// echo checks that conn is through to the fake proxy's echo
+func echo(t *testing.T, conn net.Conn) {
+	if _, err := conn.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || line != "hello\n" {
+		t.Errorf("echo = %q, %v", line, err)
+	}
+}
+
+//
+//
+func TestSocks5Dialer(t *testing.T) {
+	localhost, err := net.LookupIP("localhost")
+	if err != nil || len(localhost) == 0 {
+		t.Fatalf("localhost: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name		string
+		url			string
+		user		string				// wanted by the proxy
+		passw		string
+		addr		string
+		requested	string
+		err			int
+	}{
+		{"no auth", "socks5://%s", "", "", "10.0.0.1:22", "ip 10.0.0.1", 0},
+		{"auth", "socks5://admin:secret@%s", "admin", "secret", "[2001:db8::1]:22", "ip 2001:db8::1", 0},
+		{"bad auth", "socks5://admin:wrong@%s", "admin", "secret", "10.0.0.1:22", "", 1719},
+		{"auth required", "socks5://%s", "admin", "secret", "10.0.0.1:22", "", 1717},
+		{"socks5 resolves locally", "socks5://%s", "", "", "localhost:22", "ip " + localhost[0].String(), 0},
+		{"socks5h leaves it to the proxy", "socks5h://%s", "", "", "gw-test.example.net:22", "name gw-test.example.net", 0},
+	} {
+		requested := make(chan string, 1)
+
+		proxy := fakeProxy(t, socks5Server(tt.user, tt.passw, requested))
+
+		d, err := NewProxyDialer(fmt.Sprintf(tt.url, proxy))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn, err := d.Dial("tcp", tt.addr)
+
+		if proxyError(err) != tt.err {
+			t.Errorf("%s: err = %v, want %d", tt.name, err, tt.err)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if r := <-requested; r != tt.requested {
+			t.Errorf("%s: proxy was asked for %q, want %q", tt.name, r, tt.requested)
+		}
+
+		echo(t, conn)
+
+		conn.Close()
+	}
+}
+
+//
+//
+func TestHttpConnectDialer(t *testing.T) {
+	for _, tt := range []struct {
+		name		string
+		url			string
+		status		int
+		err			int
+	}{
+		{"connect", "http://%s", 200, 0},
+		{"auth", "http://admin:secret@%s", 200, 0},
+		{"refused", "http://%s", 407, 1733},
+	} {
+		auth := make(chan string, 1)
+
+		proxy := fakeProxy(t, func(conn net.Conn) {
+			r := bufio.NewReader(conn)
+
+			req, err := http.ReadRequest(r)
+			if err != nil || req.Method != "CONNECT" || req.Host != "10.0.0.1:22" {
+				return
+			}
+
+			auth <- req.Header.Get("Proxy-Authorization")
+
+			conn.Write([]byte("HTTP/1.1 " + strconv.Itoa(tt.status) + " " + http.StatusText(tt.status) + "\r\n\r\n"))
+
+			line, _ := r.ReadString('\n')
+			conn.Write([]byte(line))
+		})
+
+		d, err := NewProxyDialer(fmt.Sprintf(tt.url, proxy))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn, err := d.Dial("tcp", "10.0.0.1:22")
+
+		if proxyError(err) != tt.err {
+			t.Errorf("%s: err = %v, want %d", tt.name, err, tt.err)
+		}
+
+		if a := <-auth; tt.name == "auth" && a != "Basic YWRtaW46c2VjcmV0" {
+			t.Errorf("%s: Proxy-Authorization = %q", tt.name, a)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		echo(t, conn)
+
+		conn.Close()
+	}
+}
+
+//
+//
+func TestProxyHandshakeTimeout(t *testing.T) {
+	forward := &net.Dialer{Timeout: time.Second}
+
+	for _, tt := range []struct {
+		name		string
+		dialer		func(proxy string) (Dialer)
+		err			int
+	}{
+		{"socks5", func(proxy string) (Dialer) { return &socks5Dialer{proxy: proxy, timeout: 100 * time.Millisecond, forward: forward} }, 1715},
+		{"http", func(proxy string) (Dialer) { return &httpConnectDialer{proxy: proxy, timeout: 100 * time.Millisecond, forward: forward} }, 1732},
+	} {
+		d := tt.dialer(fakeProxy(t, stall))
+
+		start := time.Now()
+
+		if _, err := d.Dial("tcp", "10.0.0.1:22"); proxyError(err) != tt.err {
+			t.Errorf("%s: err = %v, want %d", tt.name, err, tt.err)
+		}
+
+		if elapsed := time.Since(start); elapsed > 5 * time.Second {
+			t.Errorf("%s: gave up after %s", tt.name, elapsed)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"net"
+	"sort"
+	"github.com/mikejac/ssh.golang/parser"
+)
+
+// RouteTable answers longest-prefix-match queries over Routes, one binary
+// trie per address family
+type RouteTable struct {
+	v4			*routeNode
+	v6			*routeNode
+	byDev		map[string]Routes
+}
+
+type routeNode struct {
+	child		[2]*routeNode
+	routes		Routes					// routes for exactly this prefix; several for ECMP
+}
+
+//
+// NewRouteTable builds a RouteTable from routes and the directly connected
+// networks of logical; a connected network routes already holds for the same
+// device is not added twice
+func NewRouteTable(routes Routes, logical LogicalInterfaces) (table *RouteTable) {
+	table = &RouteTable{v4: &routeNode{}, v6: &routeNode{}, byDev: make(map[string]Routes)}
+
+	known := make(map[string]bool)
+
+	for _, r := range routes {
+		known[r.IPNet.String() + "@" + r.Dev] = true
+	}
+
+	for _, i := range logical {
+		if i.Addr == nil || i.Mask == nil {
+			continue
+		}
+
+		ipnet := net.IPNet{IP: i.Addr.Mask(i.Mask), Mask: i.Mask}
+
+		if known[ipnet.String() + "@" + i.IfName] {
+			continue
+		}
+
+		table.Insert(NetworkRoute{Net: ipnet.String(), Dev: i.IfName, IPNet: ipnet, Family: parser.IPFamily(i.Addr), Protocol: "connected", Scope: "link"})
+	}
+
+	for _, r := range routes {
+		table.Insert(r)
+	}
+
+	return table
+}
+
+//
+// Insert adds route r; routes without a valid IPNet are ignored
+func (table *RouteTable) Insert(r NetworkRoute) {
+	ip, bits := routeKey(r.IPNet.IP)
+	if ip == nil || r.IPNet.Mask == nil {
+		return
+	}
+
+	ones, maskBits := r.IPNet.Mask.Size()
+	if maskBits != bits {
+		return
+	}
+
+	node := table.root(bits)
+
+	for i := 0; i < ones; i++ {
+		b := bit(ip, i)
+
+		if node.child[b] == nil {
+			node.child[b] = &routeNode{}
+		}
+
+		node = node.child[b]
+	}
+
+	if r.Family == 0 {
+		r.Family = parser.IPFamily(ip)
+	}
+
+	node.routes = append(node.routes, r)
+
+	sort.SliceStable(node.routes, func(i, j int) (bool) { return node.routes[i].Metric < node.routes[j].Metric })
+
+	table.byDev[r.Dev] = append(table.byDev[r.Dev], r)
+}
+
+//
+// Lookup returns the route the gateway would use for ip: the longest matching
+// prefix and, among equal prefixes, the lowest metric
+func (table *RouteTable) Lookup(ip net.IP) (route *NetworkRoute, ok bool) {
+	routes := table.LookupAll(ip)
+
+	if len(routes) == 0 {
+		return nil, false
+	}
+
+	return &routes[0], true
+}
+
+//
+// LookupAll returns every route of the longest matching prefix, e.g. all ECMP
+// next hops, ordered by metric
+func (table *RouteTable) LookupAll(ip net.IP) (routes Routes) {
+	key, bits := routeKey(ip)
+	if key == nil {
+		return nil
+	}
+
+	node := table.root(bits)
+
+	for i := 0; ; i++ {
+		if len(node.routes) > 0 {
+			routes = node.routes
+		}
+
+		if i == bits {
+			break
+		}
+
+		if node = node.child[bit(key, i)]; node == nil {
+			break
+		}
+	}
+
+	return routes
+}
+
+//
+// Networks returns the networks reachable through interface dev, connected
+// and routed, sorted
+func (table *RouteTable) Networks(dev string) (routes Routes) {
+	routes = append(routes, table.byDev[dev]...)
+
+	sort.Sort(routes)
+
+	return routes
+}
+
+//
+// Interfaces lists the interfaces that have at least one network behind them
+func (table *RouteTable) Interfaces() (devs []string) {
+	for dev := range table.byDev {
+		devs = append(devs, dev)
+	}
+
+	sort.Strings(devs)
+
+	return devs
+}
+
+/******************************************************************************************************************
+* helper functions
+*
+*/
+
+//
+//
+func (table *RouteTable) root(bits int) (*routeNode) {
+	if bits == 8 * net.IPv4len {
+		return table.v4
+	}
+
+	return table.v6
+}
+
+//
+// routeKey returns ip in its 4 or 16 byte form and its length in bits
+func routeKey(ip net.IP) (net.IP, int) {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4, 8 * net.IPv4len
+	}
+
+	if ip16 := ip.To16(); ip16 != nil {
+		return ip16, 8 * net.IPv6len
+	}
+
+	return nil, 0
+}
+
+//
+// bit returns bit i of ip, counted from the most significant
+func bit(ip net.IP, i int) (int) {
+	return int(ip[i / 8] >> uint(7 - i % 8)) & 1
+}
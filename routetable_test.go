@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+
+package sshtool
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+//
+// testRoute returns a route to network through gateway on dev
+func testRoute(network string, gateway string, dev string, metric int) (r NetworkRoute) {
+	_, ipnet, err := net.ParseCIDR(network)
+	if err != nil {
+		panic(err)
+	}
+
+	return NetworkRoute{Net: network, Gateway: gateway, Dev: dev, IPNet: *ipnet, Metric: metric}
+}
+
+//
+// testLogical returns the logical interface name with address cidr
+func testLogical(name string, cidr string) (ni NetworkLogicalInterface) {
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+
+	return NetworkLogicalInterface{IfName: name, IfIP: ip.String(), Addr: ip, Mask: ipnet.Mask}
+}
+
+//
+//
+func TestRouteTableLookup(t *testing.T) {
+	routes := Routes{
+		testRoute("0.0.0.0/0", "192.168.1.254", "eth0", 0),
+		testRoute("10.0.0.0/8", "10.0.0.2", "eth1", 0),
+		testRoute("10.20.0.0/16", "10.0.0.3", "eth1", 20),
+		testRoute("10.20.0.0/16", "10.0.0.2", "eth1", 10),
+		testRoute("10.20.30.40/32", "10.0.0.4", "eth1", 0),
+		testRoute("::/0", "2001:db8::fffe", "eth0", 1024),
+		testRoute("2001:db8:2::/48", "2001:db8::2", "eth1", 0),
+		// what ip route gives for a connected network
+		testRoute("10.0.0.0/24", "", "eth1", 0),
+	}
+
+	logical := LogicalInterfaces{
+		testLogical("eth0", "192.168.1.1/24"),
+		testLogical("eth1", "10.0.0.1/24"),
+		testLogical("eth0", "2001:db8::1/64"),
+	}
+
+	table := NewRouteTable(routes, logical)
+
+	for _, tt := range []struct {
+		ip			string
+		gateways	[]string			// of LookupAll, in order
+		dev			string
+	}{
+		{"192.168.1.10", []string{""}, "eth0"},
+		{"10.0.0.5", []string{""}, "eth1"},				// connected once, not twice
+		{"10.1.2.3", []string{"10.0.0.2"}, "eth1"},
+		{"10.20.1.1", []string{"10.0.0.2", "10.0.0.3"}, "eth1"},	// lowest metric first
+		{"10.20.30.40", []string{"10.0.0.4"}, "eth1"},
+		{"8.8.8.8", []string{"192.168.1.254"}, "eth0"},
+		{"2001:db8::10", []string{""}, "eth0"},
+		{"2001:db8:2:1::1", []string{"2001:db8::2"}, "eth1"},
+		{"2001:db8:3::1", []string{"2001:db8::fffe"}, "eth0"},
+	} {
+		var gateways []string
+
+		for _, r := range table.LookupAll(net.ParseIP(tt.ip)) {
+			gateways = append(gateways, r.Gateway)
+		}
+
+		if !reflect.DeepEqual(gateways, tt.gateways) {
+			t.Errorf("LookupAll(%s) gateways = %q, want %q", tt.ip, gateways, tt.gateways)
+		}
+
+		if r, ok := table.Lookup(net.ParseIP(tt.ip)); !ok || r.Dev != tt.dev || r.Gateway != tt.gateways[0] {
+			t.Errorf("Lookup(%s) = %+v, %v", tt.ip, r, ok)
+		}
+	}
+
+	// no default route
+	if r, ok := NewRouteTable(routes[1:5], nil).Lookup(net.ParseIP("8.8.8.8")); ok {
+		t.Errorf("Lookup(8.8.8.8) without a default route = %+v", r)
+	}
+}
+
+//
+//
+func TestRouteTableNetworks(t *testing.T) {
+	routes := Routes{
+		testRoute("10.0.0.0/24", "", "eth1", 0),
+		testRoute("10.20.0.0/16", "10.0.0.2", "eth1", 0),
+		testRoute("0.0.0.0/0", "192.168.1.254", "eth0", 0),
+	}
+
+	logical := LogicalInterfaces{
+		testLogical("eth0", "192.168.1.1/24"),
+		testLogical("eth1", "10.0.0.1/24"),
+		testLogical("eth2", "172.16.0.1/24"),
+		{IfName: "eth3"},					// no address
+	}
+
+	table := NewRouteTable(routes, logical)
+
+	if devs := table.Interfaces(); !reflect.DeepEqual(devs, []string{"eth0", "eth1", "eth2"}) {
+		t.Errorf("Interfaces() = %q", devs)
+	}
+
+	for _, tt := range []struct {
+		dev			string
+		networks	[]string
+	}{
+		{"eth0", []string{"0.0.0.0/0", "192.168.1.0/24"}},
+		{"eth1", []string{"10.0.0.0/24", "10.20.0.0/16"}},
+		{"eth2", []string{"172.16.0.0/24"}},
+		{"eth3", nil},
+	} {
+		var networks []string
+
+		for _, r := range table.Networks(tt.dev) {
+			networks = append(networks, r.IPNet.String())
+		}
+
+		if !reflect.DeepEqual(networks, tt.networks) {
+			t.Errorf("Networks(%s) = %q, want %q", tt.dev, networks, tt.networks)
+		}
+	}
+}
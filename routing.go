@@ -0,0 +1,135 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"fmt"
+	"github.com/mikejac/ssh.golang/parser"
+)
+
+// The routing types are defined, and parsed, in the parser package
+type ClishRoute		= parser.ClishRoute
+type ClishRoutes		= parser.ClishRoutes
+type OSPFNeighbor	= parser.OSPFNeighbor
+type OSPFInterface	= parser.OSPFInterface
+type BGPPeer			= parser.BGPPeer
+type StaticRoute		= parser.StaticRoute
+type StaticRoutes		= parser.StaticRoutes
+
+//
+// 3700
+func (sshAction *SshAction) GetClishRoutes() (routes ClishRoutes, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetClishRoutes(): begin\n") }
+
+	for _, cmd := range []string{"show route", "show ipv6 route"} {
+		var result string
+
+		if result, err = sshAction.clishExecute(cmd, 20); err != nil {
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetClishRoutes(): unable to execute '%s'\n", cmd) }
+			return nil, New(3700, err.Error())
+		}
+
+		r, warnings := parser.ParseShowRoute(result)
+
+		routes = append(routes, r...)
+		sshAction.warn(warnings)
+	}
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetClishRoutes(): end; %d routes\n", len(routes)) }
+
+	return routes, nil
+}
+
+//
+// 3710
+func (sshAction *SshAction) GetOSPFNeighbors() (neighbors []OSPFNeighbor, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOSPFNeighbors(): begin\n") }
+
+	result, err := sshAction.clishExecute("show ospf neighbors", 20)
+	if err != nil {
+		return nil, New(3710, err.Error())
+	}
+
+	neighbors, warnings := parser.ParseOSPFNeighbors(result)
+
+	sshAction.warn(warnings)
+
+	return neighbors, nil
+}
+
+//
+// 3720
+func (sshAction *SshAction) GetOSPFInterfaces() (interfaces []OSPFInterface, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetOSPFInterfaces(): begin\n") }
+
+	result, err := sshAction.clishExecute("show ospf interfaces", 20)
+	if err != nil {
+		return nil, New(3720, err.Error())
+	}
+
+	interfaces, warnings := parser.ParseOSPFInterfaces(result)
+
+	sshAction.warn(warnings)
+
+	return interfaces, nil
+}
+
+//
+// 3730
+func (sshAction *SshAction) GetBGPPeers() (peers []BGPPeer, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetBGPPeers(): begin\n") }
+
+	result, err := sshAction.clishExecute("show bgp peers", 20)
+	if err != nil {
+		return nil, New(3730, err.Error())
+	}
+
+	peers, warnings := parser.ParseBGPPeers(result)
+
+	sshAction.warn(warnings)
+
+	return peers, nil
+}
+
+//
+// 3740
+func (sshAction *SshAction) GetStaticRoutes() (routes StaticRoutes, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetStaticRoutes(): begin\n") }
+
+	for _, cmd := range []string{"show configuration static-route", "show configuration ipv6 static-route"} {
+		var result string
+
+		if result, err = sshAction.clishExecute(cmd, 20); err != nil {
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetStaticRoutes(): unable to execute '%s'\n", cmd) }
+			return nil, New(3740, err.Error())
+		}
+
+		r, warnings := parser.ParseStaticRouteConfig(result)
+
+		routes = append(routes, r...)
+		sshAction.warn(warnings)
+	}
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetStaticRoutes(): end; %d next hops\n", len(routes)) }
+
+	return routes, nil
+}
@@ -0,0 +1,348 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Snapshot is the inventory of one gateway at one point in time. A collector
+// that failed leaves its field empty and its error in Errors.
+type Snapshot struct {
+	Host			string						`json:"host"`
+	Taken			time.Time					`json:"taken"`
+
+	OsClass			OsClass						`json:"os_class"`
+	OsType			OsType						`json:"os_type"`
+	Info			*VersionInfo				`json:"info,omitempty"`
+	Interfaces		LogicalInterfaces			`json:"interfaces,omitempty"`
+	Physical		PhysicalInterfaces			`json:"physical,omitempty"`
+	Routes			Routes						`json:"routes,omitempty"`
+	ARP				ArpTable					`json:"arp,omitempty"`
+	CPHA			*CphaData					`json:"cpha,omitempty"`
+
+	Errors			map[string]string			`json:"errors,omitempty"`		// keyed by collector, e.g. "routes"
+}
+
+// SnapshotChange is one difference between two snapshots
+type SnapshotChange struct {
+	Section			string						`json:"section"`		// "os", "info", "interface", "physical", "route", "arp" or "cpha"
+	Item			string						`json:"item"`
+	Change			string						`json:"change"`		// "added", "removed" or "changed"
+	Old				string						`json:"old,omitempty"`
+	New				string						`json:"new,omitempty"`
+}
+
+type SnapshotDiff []SnapshotChange
+
+//
+// TakeSnapshot runs the collectors one after the other
+func (sshAction *SshAction) TakeSnapshot() (snapshot *Snapshot, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::TakeSnapshot(): begin\n") }
+
+	snapshot = &Snapshot{Host: sshAction.host, Taken: time.Now().UTC(), Errors: make(map[string]string)}
+
+	failed := func(what string, err error) {
+		if sshAction.verbose > 0 { fmt.Printf("SshAction::TakeSnapshot(): %s: %s\n", what, err.Error()) }
+
+		snapshot.Errors[what] = err.Error()
+	}
+
+	if snapshot.OsClass, snapshot.OsType, err = sshAction.GetOS(); err != nil {
+		failed("os", err)
+	}
+
+	if snapshot.Info, err = sshAction.GetInfo(); err != nil {
+		failed("info", err)
+	}
+
+	if snapshot.Interfaces, err = sshAction.GetInterfaces(); err != nil {
+		failed("interfaces", err)
+	}
+
+	if snapshot.Physical, err = sshAction.GetPhyInterfaceDetails(); err != nil {
+		failed("physical", err)
+	}
+
+	if snapshot.Routes, err = sshAction.GetRoutes(); err != nil {
+		failed("routes", err)
+	}
+
+	if snapshot.ARP, err = sshAction.GetARP(); err != nil {
+		failed("arp", err)
+	}
+
+	if snapshot.CPHA, err = sshAction.GetCPHA(); err != nil {
+		failed("cpha", err)
+	}
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::TakeSnapshot(): end; %d collectors failed\n", len(snapshot.Errors)) }
+
+	return snapshot, nil
+}
+
+//
+//
+func (snapshot *Snapshot) JSON() ([]byte, error) {
+	return json.MarshalIndent(snapshot, "", "  ")
+}
+
+//
+//
+func LoadSnapshot(data []byte) (snapshot *Snapshot, err error) {
+	snapshot = &Snapshot{}
+
+	if err = json.Unmarshal(data, snapshot); err != nil {
+		return nil, New(9000, err.Error())
+	}
+
+	return snapshot, nil
+}
+
+//
+// DiffSnapshots reports what was added, removed or changed from old to new.
+// Sections a collector failed for in either snapshot are skipped rather than
+// reported as removed.
+func DiffSnapshots(old *Snapshot, new *Snapshot) (diff SnapshotDiff) {
+	skip := func(collector string) (bool) {
+		_, a := old.Errors[collector]
+		_, b := new.Errors[collector]
+
+		return a || b
+	}
+
+	if !skip("os") {
+		diff.compare("os", snapshotOS(old), snapshotOS(new))
+	}
+
+	if !skip("info") {
+		diff.compare("info", snapshotInfo(old.Info), snapshotInfo(new.Info))
+	}
+
+	if !skip("interfaces") {
+		diff.compare("interface", snapshotInterfaces(old.Interfaces), snapshotInterfaces(new.Interfaces))
+	}
+
+	if !skip("physical") {
+		diff.compare("physical", snapshotPhysical(old.Physical), snapshotPhysical(new.Physical))
+	}
+
+	if !skip("routes") {
+		diff.compare("route", snapshotRoutes(old.Routes), snapshotRoutes(new.Routes))
+	}
+
+	if !skip("arp") {
+		diff.compare("arp", snapshotARP(old.ARP), snapshotARP(new.ARP))
+	}
+
+	if !skip("cpha") {
+		diff.compare("cpha", snapshotCPHA(old.CPHA), snapshotCPHA(new.CPHA))
+	}
+
+	return diff
+}
+
+//
+//
+func (diff SnapshotDiff) JSON() ([]byte, error) {
+	return json.MarshalIndent(diff, "", "  ")
+}
+
+//
+// String gives one line per change, e.g. '~ route 10.1.0.0/16: 10.0.0.1@eth1 -> 10.0.0.2@eth1'
+func (diff SnapshotDiff) String() (string) {
+	var lines []string
+
+	for _, c := range diff {
+		switch c.Change {
+			case "added":
+				lines = append(lines, "+ " + c.Section + " " + c.Item + ": " + c.New)
+			case "removed":
+				lines = append(lines, "- " + c.Section + " " + c.Item + ": " + c.Old)
+			default:
+				lines = append(lines, "~ " + c.Section + " " + c.Item + ": " + c.Old + " -> " + c.New)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+/******************************************************************************************************************
+* helper functions
+*
+*/
+
+//
+//
+func (diff *SnapshotDiff) compare(section string, old map[string]string, new map[string]string) {
+	keys := make(map[string]bool)
+
+	for k := range old {
+		keys[k] = true
+	}
+
+	for k := range new {
+		keys[k] = true
+	}
+
+	var sorted []string
+
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		o, inOld := old[k]
+		n, inNew := new[k]
+
+		switch {
+			case !inOld:
+				*diff = append(*diff, SnapshotChange{Section: section, Item: k, Change: "added", New: n})
+			case !inNew:
+				*diff = append(*diff, SnapshotChange{Section: section, Item: k, Change: "removed", Old: o})
+			case o != n:
+				*diff = append(*diff, SnapshotChange{Section: section, Item: k, Change: "changed", Old: o, New: n})
+		}
+	}
+}
+
+//
+//
+func snapshotOS(s *Snapshot) (map[string]string) {
+	return map[string]string{"class": strconv.Itoa(int(s.OsClass)), "type": strconv.Itoa(int(s.OsType))}
+}
+
+//
+//
+func snapshotInfo(info *VersionInfo) (v map[string]string) {
+	v = make(map[string]string)
+
+	if info == nil {
+		return v
+	}
+
+	v["version"]	= info.Version
+	v["build"]		= strconv.Itoa(info.Build)
+	v["take"]		= strconv.Itoa(info.Take)
+	v["kernel"]		= info.Kernel
+	v["model"]		= info.Model
+	v["edition"]	= info.Edition
+
+	return v
+}
+
+//
+//
+func snapshotInterfaces(logical LogicalInterfaces) (v map[string]string) {
+	l := make(map[string][]string)
+
+	for _, i := range logical {
+		l[i.IfName] = append(l[i.IfName], i.IfIP)
+	}
+
+	return joinSorted(l)
+}
+
+//
+//
+func snapshotPhysical(physical PhysicalInterfaces) (v map[string]string) {
+	v = make(map[string]string)
+
+	for _, p := range physical {
+		// e.g. "bond0.300.10" for QinQ, like the Linux device names
+		key := p.IfName
+
+		if p.OuterVLAN != "" {
+			key += "." + p.OuterVLAN
+		}
+
+		if p.VLAN != "" {
+			key += "." + p.VLAN
+		}
+
+		v[key] = fmt.Sprintf("admin=%v oper=%v mac=%s mtu=%d speed=%d duplex=%s master=%s", p.AdminUp, p.OperUp, p.MAC, p.MTU, p.Speed, p.Duplex, p.Master)
+	}
+
+	return v
+}
+
+//
+//
+func snapshotRoutes(routes Routes) (v map[string]string) {
+	hops := make(map[string][]string)
+
+	for _, r := range routes {
+		hops[r.Net] = append(hops[r.Net], fmt.Sprintf("%s@%s metric %d", r.Gateway, r.Dev, r.Metric))
+	}
+
+	return joinSorted(hops)
+}
+
+//
+//
+func snapshotARP(arp ArpTable) (v map[string]string) {
+	macs := make(map[string][]string)
+
+	for _, a := range arp {
+		key := a.IP
+
+		if a.Proxy {
+			key += " (proxy)"
+		}
+
+		macs[key] = append(macs[key], a.MAC + "@" + a.IfName)
+	}
+
+	return joinSorted(macs)
+}
+
+//
+//
+func snapshotCPHA(cpha *CphaData) (v map[string]string) {
+	v = make(map[string]string)
+
+	if cpha == nil {
+		return v
+	}
+
+	v["status"]	= cpha.Status
+	v["mode"]	= cpha.Mode
+	v["sync"]	= cpha.SyncStatus
+	v["pnotes"]	= strings.Join(cpha.ActivePnotes, ",")
+
+	for _, m := range cpha.Members {
+		v["member " + strconv.Itoa(m.ID)] = m.State
+	}
+
+	for _, s := range cpha.SGMs {
+		v["sgm " + s.ID] = s.State
+	}
+
+	return v
+}
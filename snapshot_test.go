@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+
+package sshtool
+
+import (
+	"testing"
+)
+
+//
+//
+func TestDiffSnapshotsQinQ(t *testing.T) {
+	old := &Snapshot{Physical: PhysicalInterfaces{
+		{IfName: "bond0", VLAN: "10", OuterVLAN: "300", MTU: 1500},
+		{IfName: "bond0", VLAN: "10", OuterVLAN: "400", MTU: 1500},
+		{IfName: "bond0", VLAN: "10", MTU: 1500},
+	}}
+
+	new := &Snapshot{Physical: PhysicalInterfaces{
+		{IfName: "bond0", VLAN: "10", OuterVLAN: "300", MTU: 1500},
+		{IfName: "bond0", VLAN: "10", OuterVLAN: "400", MTU: 9000},
+	}}
+
+	diff := DiffSnapshots(old, new)
+
+	if len(diff) != 2 {
+		t.Fatalf("diff = %v", diff)
+	}
+
+	if diff[0].Item != "bond0.10" || diff[0].Change != "removed" {
+		t.Errorf("diff[0] = %+v, want bond0.10 removed", diff[0])
+	}
+
+	if diff[1].Item != "bond0.400.10" || diff[1].Change != "changed" {
+		t.Errorf("diff[1] = %+v, want bond0.400.10 changed", diff[1])
+	}
+}
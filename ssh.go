@@ -24,11 +24,13 @@ package sshtool
 import (
 	"fmt"
 	"io"
+	"net"
 	"time"
 	"strings"
 	"strconv"
     "regexp"
 	"golang.org/x/crypto/ssh"
+	"github.com/mikejac/ssh.golang/parser"
 )
 
 const (
@@ -39,6 +41,8 @@ const (
 	PlatformXBM			= iota
 	PlatformCPM			= iota
 	PlatformAPM			= iota
+	PlatformGaiaEmbedded	= iota				// SMB appliances, CLISH
+	PlatformScalable		= iota				// Maestro / 61k / 64k security group, gclish
 )
 
 type Platform int
@@ -59,6 +63,7 @@ type SshAction struct {
 	port		int
 	
 	client		*ssh.Client
+	jumps		[]*ssh.Client				// ProxyJump hops, outermost first
 	session	*ssh.Session
 	in			io.WriteCloser
 	out			io.Reader
@@ -68,6 +73,8 @@ type SshAction struct {
 	prompt2 	*regexp.Regexp
 	prompt3 	*regexp.Regexp
 	prompt4 	*regexp.Regexp
+	prompt5 	*regexp.Regexp
+	prompt6 	*regexp.Regexp
 	
 	currentPrompt	string
 	
@@ -76,24 +83,42 @@ type SshAction struct {
 	splat_cpshell	bool
 	xbm				bool
 	ipso			bool
+	embedded		bool
+	
+	warnings		parser.Warnings			// parse warnings since the last call of Warnings
 	
 	platform		Platform
 }
 
 func NewSshAction(host string, user string, passw string, su_passw string, port int, verbose int) (sshAction *SshAction, err error) {
-	cc := &ssh.Config{
-		Ciphers: []string{"aes256-ctr", "aes128-cbc", "hmac-sha1", "none"},
+	return NewSshActionDialer(host, user, passw, su_passw, port, verbose, nil)
+}
+
+//
+// NewSshActionProxy connects through a SOCKS5 or HTTP CONNECT proxy, see NewProxyDialer
+func NewSshActionProxy(host string, user string, passw string, su_passw string, port int, verbose int, proxyURL string) (sshAction *SshAction, err error) {
+	dialer, err := NewProxyDialer(proxyURL)
+	if err != nil {
+		return nil, err
 	}
 	
-	config := &ssh.ClientConfig{
-		Config: *cc,
-	    User: user,
-	    Auth: []ssh.AuthMethod{
-	        ssh.Password(passw),
-	    },
+	return NewSshActionDialer(host, user, passw, su_passw, port, verbose, dialer)
+}
+
+//
+// NewSshActionDialer opens the TCP connection using dialer; a nil dialer dials directly
+func NewSshActionDialer(host string, user string, passw string, su_passw string, port int, verbose int, dialer Dialer) (sshAction *SshAction, err error) {
+	auth := []ssh.AuthMethod{
+		ssh.Password(passw),
 	}
 	
-	client, err := ssh.Dial("tcp", host + ":" + strconv.Itoa(port), config)
+	return newSshAction(host, user, passw, su_passw, port, verbose, dialer, auth)
+}
+
+//
+//
+func newSshAction(host string, user string, passw string, su_passw string, port int, verbose int, dialer Dialer, auth []ssh.AuthMethod) (sshAction *SshAction, err error) {
+	client, err := sshDial(net.JoinHostPort(host, strconv.Itoa(port)), sshClientConfig(user, auth), dialer)
 	if err != nil {
 		//fmt.Println("NewSshAction(): could not connect to host: " + err.Error())
 		return nil, err
@@ -110,12 +135,55 @@ func NewSshAction(host string, user string, passw string, su_passw string, port
 		prompt:	-1,
 	}
 		
+	sshAction.initPrompts()
+
+	return sshAction, nil
+}
+
+//
+// initPrompts compiles the prompts waitfor() and expertEnter() look for
+func (sshAction *SshAction) initPrompts() {
 	sshAction.prompt1 = regexp.MustCompile(`\w> `)						// GAIA CLISH
 	sshAction.prompt2 = regexp.MustCompile(`\w]# `)						// Expert or SPLAT CPSHELL or IPSO
 	sshAction.prompt3 = regexp.MustCompile(`\w# `)						// CrossBeam CPM
 	sshAction.prompt4 = regexp.MustCompile(`\w] ~\$ `)					// CrossBeam APM
+	sshAction.prompt5 = regexp.MustCompile(`(^|\n)[~/]\S* [#$] `)			// Gaia Embedded busybox shell
+	sshAction.prompt6 = regexp.MustCompile(`\[Global\] \S+ ?> `)				// Scalable Platform gclish
+}
 
-	return sshAction, nil
+//
+//
+func sshClientConfig(user string, auth []ssh.AuthMethod) (*ssh.ClientConfig) {
+	cc := &ssh.Config{
+		Ciphers: []string{"aes256-ctr", "aes128-cbc", "hmac-sha1", "none"},
+	}
+	
+	return &ssh.ClientConfig{
+		Config: *cc,
+	    User: user,
+	    Auth: auth,
+	}
+}
+
+//
+// sshDial connects to addr, through dialer when it is not nil
+func sshDial(addr string, config *ssh.ClientConfig, dialer Dialer) (*ssh.Client, error) {
+	if dialer == nil {
+		return ssh.Dial("tcp", addr, config)
+	}
+	
+	conn, err := dialer.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	
+	return ssh.NewClient(c, chans, reqs), nil
 }
 
 //
@@ -170,6 +238,8 @@ func (sshAction *SshAction) Connect() (error) {
 	
 	if sshAction.verbose > 0 { fmt.Printf("SshAction::Connect(): prompt = %d\n", sshAction.prompt) }
 	
+	sshAction.detectEmbedded()
+	
 	return sshAction.detect()
 }
 
@@ -192,6 +262,7 @@ func (sshAction *SshAction) Disconnect() (error) {
 
 	sshAction.session.Close()
 	sshAction.client.Close()
+	closeJumps(sshAction.jumps)
 	
 	if sshAction.verbose > 0 { fmt.Printf("SshAction::Disconnect(): end\n") }
 
@@ -235,6 +306,21 @@ func (sshAction *SshAction) detect() (error) {
 		
 		ok						= true
 		sshAction.platform	= PlatformXBM
+	} else if sshAction.prompt == 6 {
+		if sshAction.verbose > 0 { fmt.Println("SshAction::detect(): Gaia Embedded CLISH") }
+		
+		ok						= true
+		sshAction.platform	= PlatformGaiaEmbedded
+	} else if sshAction.prompt == 7 {
+		if sshAction.verbose > 0 { fmt.Println("SshAction::detect(): Gaia Embedded Expert-mode") }
+		
+		ok						= true
+		sshAction.platform	= PlatformExpert
+	} else if sshAction.prompt == 8 {
+		if sshAction.verbose > 0 { fmt.Println("SshAction::detect(): Scalable Platform gclish") }
+		
+		ok						= true
+		sshAction.platform	= PlatformScalable
 	}
 
 	if ok {
@@ -293,6 +379,13 @@ func (sshAction *SshAction) expertEnter() (error) {
 					sshAction.prompt = 2
 					if sshAction.verbose > 0 { fmt.Printf("SshAction::expertEnter(): found prompt 2\n") }
 					
+					// signal parent we're done
+					done <- sshAction.findPrompt(string(buf[:]))
+					break
+				} else if sshAction.prompt5.MatchString(string(buf[:])) {
+					sshAction.prompt = 7
+					if sshAction.verbose > 0 { fmt.Printf("SshAction::expertEnter(): found prompt 7\n") }
+					
 					// signal parent we're done
 					done <- sshAction.findPrompt(string(buf[:]))
 					break
@@ -323,6 +416,17 @@ func (sshAction *SshAction) expertEnter() (error) {
 			err = New(1201, "timeout")
 			break
 	}
+	
+	// no banner was seen at login, but the busybox prompt may still be Gaia Embedded's
+	if err == nil && sshAction.prompt == 7 && !sshAction.embedded && sshAction.confirmEmbedded() {
+		if sshAction.verbose > 0 { fmt.Printf("SshAction::expertEnter(): Gaia Embedded detected from its prompt\n") }
+		
+		sshAction.embedded = true
+		
+		if sshAction.platform == PlatformGAiA {
+			sshAction.platform = PlatformGaiaEmbedded
+		}
+	}
 		
 	return err
 }
@@ -354,11 +458,13 @@ func (sshAction *SshAction) waitfor() (error) {
 		var hint2 *regexp.Regexp
 		var hint3 *regexp.Regexp
 		var hint4 *regexp.Regexp
+		var hint5 *regexp.Regexp
 	
 		hint1 = regexp.MustCompile(`\\? for list of commands`)			// SPLAT CPSHELL
 		hint2 = regexp.MustCompile(`Active Alarms Summary`)				// CrossBeam
 		hint3 = regexp.MustCompile(`IPSO `)								// IPSO
 		hint4 = regexp.MustCompile(`Terminal type\\?`)					// IPSO
+		hint5 = regexp.MustCompile(`Gaia Embedded`)						// SMB appliances
 		
 		buf := make([]byte, inBufferSize)
 		t   := 0
@@ -387,10 +493,31 @@ func (sshAction *SshAction) waitfor() (error) {
 				//if sshAction.verbose > 0 { fmt.Println(string(buf[:])) }
 				if sshAction.verbose > 0 { fmt.Println(str) }
 				
-				//if sshAction.prompt1.MatchString(string(buf[:])) {
-				if sshAction.prompt1.MatchString(str) {
-					sshAction.prompt = 1
-					if sshAction.verbose > 0 { fmt.Printf("SshAction::waitfor(): found prompt 1\n") }
+				// the banner is only a hint: the Gaia Embedded CLISH prompt is that of
+				// Gaia, while its busybox prompt (prompt5) is confirmed by detectEmbedded()
+				if !sshAction.embedded && hint5.MatchString(str) {
+					if sshAction.verbose > 0 { fmt.Printf("SshAction::waitfor(): found hint 5\n") }
+
+					sshAction.embedded = true
+				}
+				
+				// gclish prompts also match prompt1, so look for them first
+				if sshAction.prompt6.MatchString(str) {
+					sshAction.prompt = 8
+					if sshAction.verbose > 0 { fmt.Printf("SshAction::waitfor(): found prompt 8\n") }
+					
+					// signal parent we're done
+					done <- sshAction.findPrompt(string(buf[:]))
+					break
+				//} else if sshAction.prompt1.MatchString(string(buf[:])) {
+				} else if sshAction.prompt1.MatchString(str) {
+					if sshAction.embedded {
+						sshAction.prompt = 6
+						if sshAction.verbose > 0 { fmt.Printf("SshAction::waitfor(): found prompt 6\n") }
+					} else {
+						sshAction.prompt = 1
+						if sshAction.verbose > 0 { fmt.Printf("SshAction::waitfor(): found prompt 1\n") }
+					}
 					
 					// signal parent we're done
 					done <- sshAction.findPrompt(string(buf[:]))
@@ -400,6 +527,9 @@ func (sshAction *SshAction) waitfor() (error) {
 					if sshAction.ipso {
 						sshAction.prompt = 4
 						if sshAction.verbose > 0 { fmt.Printf("SshAction::waitfor(): found prompt 4\n") }						
+					} else if sshAction.embedded {
+						sshAction.prompt = 7
+						if sshAction.verbose > 0 { fmt.Printf("SshAction::waitfor(): found prompt 7\n") }
 					} else {
 						sshAction.prompt = 2
 						if sshAction.verbose > 0 { fmt.Printf("SshAction::waitfor(): found prompt 2\n") }
@@ -421,6 +551,14 @@ func (sshAction *SshAction) waitfor() (error) {
 					sshAction.prompt = 5
 					if sshAction.verbose > 0 { fmt.Printf("SshAction::waitfor(): found prompt 5\n") }
 					
+					// signal parent we're done
+					done <- sshAction.findPrompt(string(buf[:]))
+					break
+				} else if sshAction.prompt5.MatchString(str) {
+					// any busybox shell looks like this; detectEmbedded() confirms it
+					sshAction.prompt = 7
+					if sshAction.verbose > 0 { fmt.Printf("SshAction::waitfor(): found prompt 7\n") }
+					
 					// signal parent we're done
 					done <- sshAction.findPrompt(string(buf[:]))
 					break
@@ -472,6 +610,34 @@ func (sshAction *SshAction) waitfor() (error) {
 	return err
 }
 
+//
+// detectEmbedded confirms that a busybox prompt seen without the Gaia Embedded
+// banner belongs to Gaia Embedded; any other busybox or Linux shell is
+// plain Expert-mode
+func (sshAction *SshAction) detectEmbedded() {
+	if sshAction.prompt != 7 || sshAction.embedded {
+		return
+	}
+	
+	if sshAction.confirmEmbedded() {
+		if sshAction.verbose > 0 { fmt.Printf("SshAction::detectEmbedded(): Gaia Embedded\n") }
+		
+		sshAction.embedded = true
+	} else {
+		if sshAction.verbose > 0 { fmt.Printf("SshAction::detectEmbedded(): not Gaia Embedded\n") }
+		
+		sshAction.prompt = 2
+	}
+}
+
+//
+// confirmEmbedded looks for the Gaia Embedded OS daemon
+func (sshAction *SshAction) confirmEmbedded() (bool) {
+	result, err := sshAction.execute("[ -f /pfrm2.0/bin/cposd ] && echo yes || echo no", 5)
+	
+	return err == nil && strings.TrimSpace(result) == "yes"
+}
+
 //
 //
 func (sshAction *SshAction) findPrompt(str string) (error) {
@@ -581,3 +747,89 @@ func (sshAction *SshAction) execute(cmd string, timeout int) (result string, err
 	
 	return result, err
 }
+
+//
+// expertExecute runs cmd from the expert shell, entering and leaving expert-mode
+// on platforms that log in to CLISH
+func (sshAction *SshAction) expertExecute(cmd string, timeout int) (result string, err error) {
+	switch sshAction.platform {
+		case PlatformGAiA:
+			fallthrough
+			
+		case PlatformGaiaEmbedded:
+			fallthrough
+			
+		case PlatformScalable:
+			fallthrough
+			
+		case PlatformSplatCPSHELL:
+			if err = sshAction.expertEnter(); err != nil {
+				return "", err
+			}
+			
+			result, err = sshAction.execute(cmd, timeout)
+			
+			sshAction.expertExit()
+			
+		case PlatformExpert:
+			fallthrough
+			
+		case PlatformIPSO:
+			result, err = sshAction.execute(cmd, timeout)
+			
+		default:
+			return "", New(1610, "no expert shell on platform")
+	}
+	
+	return result, err
+}
+
+//
+// clishExecute runs cmd in CLISH, through 'clish -c' when logged in to the
+// expert shell
+func (sshAction *SshAction) clishExecute(cmd string, timeout int) (result string, err error) {
+	switch sshAction.platform {
+		case PlatformGAiA:
+			fallthrough
+			
+		case PlatformGaiaEmbedded:
+			fallthrough
+			
+		case PlatformScalable:
+			result, err = sshAction.execute(cmd, timeout)
+			
+		case PlatformExpert:
+			result, err = sshAction.execute("clish -c " + shellQuote(cmd) + " 2>&1", timeout)
+			
+		default:
+			return "", New(1620, "no clish on platform")
+	}
+	
+	return result, err
+}
+
+//
+// Warnings returns the lines the collectors could not parse since the
+// previous call
+func (sshAction *SshAction) Warnings() (warnings parser.Warnings) {
+	warnings = sshAction.warnings
+	sshAction.warnings = nil
+	
+	return warnings
+}
+
+//
+//
+func (sshAction *SshAction) warn(warnings parser.Warnings) {
+	for _, w := range warnings {
+		if sshAction.verbose > 0 { fmt.Printf("SshAction::warn(): %s\n", w.String()) }
+	}
+	
+	sshAction.warnings = append(sshAction.warnings, warnings...)
+}
+
+//
+// shellQuote single-quotes s for the expert shell, e.g. it's becomes 'it'\''s'
+func shellQuote(s string) (string) {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+
+package sshtool
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+const confirmEmbeddedCommand = "[ -f /pfrm2.0/bin/cposd ] && echo yes || echo no"
+
+// promptShell echoes every command written to it, followed by its canned
+// output and prompt
+type promptShell struct {
+	prompt	string
+	outputs	map[string]string			// keyed by command
+	w		*io.PipeWriter
+}
+
+//
+//
+func (shell *promptShell) Write(p []byte) (int, error) {
+	go shell.w.Write([]byte(string(p) + shell.outputs[strings.TrimSuffix(string(p), "\n")] + shell.prompt))
+
+	return len(p), nil
+}
+
+//
+//
+func (shell *promptShell) Close() (error) {
+	return shell.w.Close()
+}
+
+//
+// newPromptSession returns an SshAction talking to a promptShell, with login
+// as the first thing it reads
+func newPromptSession(login string, prompt string, outputs map[string]string) (sshAction *SshAction) {
+	r, w := io.Pipe()
+
+	sshAction = &SshAction{in: &promptShell{prompt, outputs, w}, out: r, prompt: -1}
+	sshAction.initPrompts()
+
+	go w.Write([]byte(login))
+
+	return sshAction
+}
+
+//
+//
+func TestWaitfor(t *testing.T) {
+	for _, tt := range []struct {
+		login		string
+		prompt		int
+		embedded	bool
+		platform	Platform
+	}{
+		{"Last login: Sun Oct 18 10:00:00 2026\ngw-test> ", 1, false, PlatformGAiA},
+		{"Last login: Sun Oct 18 10:00:00 2026\n[Expert@gw-test:0]# ", 2, false, PlatformExpert},
+		{"Welcome to Check Point Gaia Embedded\ngw-test> ", 6, true, PlatformGaiaEmbedded},
+		{"Welcome to Check Point Gaia Embedded\n[Expert@gw-test]# ", 7, true, PlatformExpert},
+		{"Welcome to Check Point Gaia Embedded\n/ # ", 7, true, PlatformExpert},
+		// no banner: the busybox prompt is left to detectEmbedded()
+		{"Last login: Sun Oct 18 10:00:00 2026\n/ # ", 7, false, PlatformExpert},
+		{"Last login: Sun Oct 18 10:00:00 2026\n~ $ ", 7, false, PlatformExpert},
+		{"Last login: Sun Oct 18 10:00:00 2026\n[Global] gw-test-ch01-01 > ", 8, false, PlatformScalable},
+	} {
+		sshAction := newPromptSession(tt.login, "", nil)
+
+		if err := sshAction.waitfor(); err != nil {
+			t.Fatalf("%q: %s", tt.login, err.Error())
+		}
+
+		if err := sshAction.detect(); err != nil {
+			t.Fatalf("%q: %s", tt.login, err.Error())
+		}
+
+		if sshAction.prompt != tt.prompt || sshAction.embedded != tt.embedded || sshAction.platform != tt.platform {
+			t.Errorf("%q: prompt = %d, embedded = %v, platform = %d, want %d, %v, %d", tt.login, sshAction.prompt, sshAction.embedded, sshAction.platform, tt.prompt, tt.embedded, tt.platform)
+		}
+
+		sshAction.in.Close()
+	}
+}
+
+//
+//
+func TestDetectEmbedded(t *testing.T) {
+	for _, tt := range []struct {
+		answer		string
+		prompt		int
+		embedded	bool
+	}{
+		{"yes\n", 7, true},
+		{"no\n", 2, false},			// some other busybox box
+	} {
+		sshAction := newPromptSession("Last login: Sun Oct 18 10:00:00 2026\n/ # ", "/ # ", map[string]string{confirmEmbeddedCommand: tt.answer})
+
+		if err := sshAction.waitfor(); err != nil {
+			t.Fatal(err)
+		}
+
+		sshAction.detectEmbedded()
+
+		if err := sshAction.detect(); err != nil {
+			t.Fatal(err)
+		}
+
+		if sshAction.prompt != tt.prompt || sshAction.embedded != tt.embedded || sshAction.platform != PlatformExpert {
+			t.Errorf("%q: prompt = %d, embedded = %v, platform = %d, want %d, %v, %d", tt.answer, sshAction.prompt, sshAction.embedded, sshAction.platform, tt.prompt, tt.embedded, PlatformExpert)
+		}
+
+		sshAction.in.Close()
+	}
+}
+
+//
+//
+func TestExpertEnterEmbedded(t *testing.T) {
+	for _, tt := range []struct {
+		answer		string
+		embedded	bool
+		platform	Platform
+	}{
+		{"yes\n", true, PlatformGaiaEmbedded},
+		{"no\n", false, PlatformGAiA},
+	} {
+		// logged in to CLISH without seeing the Gaia Embedded banner
+		sshAction := newPromptSession("", "/ # ", map[string]string{confirmEmbeddedCommand: tt.answer})
+		sshAction.prompt   = 1
+		sshAction.platform = PlatformGAiA
+
+		if err := sshAction.expertEnter(); err != nil {
+			t.Fatal(err)
+		}
+
+		if sshAction.prompt != 7 || sshAction.embedded != tt.embedded || sshAction.platform != tt.platform {
+			t.Errorf("%q: prompt = %d, embedded = %v, platform = %d, want 7, %v, %d", tt.answer, sshAction.prompt, sshAction.embedded, sshAction.platform, tt.embedded, tt.platform)
+		}
+
+		if sshAction.currentPrompt != "\n/ # " {
+			t.Errorf("%q: currentPrompt = %q", tt.answer, sshAction.currentPrompt)
+		}
+
+		sshAction.in.Close()
+	}
+}
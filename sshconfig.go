@@ -0,0 +1,427 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	sshConfigMaxInclude	int = 16
+	sshConfigMaxJumps		int = 8
+)
+
+// SshConfigHost is the outcome of resolving a host alias through an OpenSSH
+// client configuration file
+type SshConfigHost struct {
+	Alias				string
+	HostName			string
+	Port				int
+	User				string
+	IdentityFiles	[]string
+	ProxyJump		string
+}
+
+type sshConfigBlock struct {
+	patterns	[]string
+	options	[][2]string
+}
+
+// SshConfig holds the Host blocks of an ssh_config file, in file order
+type SshConfig struct {
+	blocks		[]sshConfigBlock
+}
+
+//
+// LoadSshConfig reads an ssh_config file; an empty path means ~/.ssh/config
+func LoadSshConfig(path string) (config *SshConfig, err error) {
+	if path == "" {
+		path = filepath.Join(sshHomeDir(), ".ssh", "config")
+	}
+
+	config = &SshConfig{}
+
+	// options before the first Host line apply to every host
+	config.blocks = append(config.blocks, sshConfigBlock{patterns: []string{"*"}})
+
+	if err = config.load(expandHome(path), 0); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+//
+//
+func (config *SshConfig) load(path string, depth int) (error) {
+	if depth > sshConfigMaxInclude {
+		return New(1800, "too many nested Include directives")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return New(1801, err.Error())
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		keyword, value := sshConfigSplit(scanner.Text())
+		if keyword == "" {
+			continue
+		}
+
+		switch keyword {
+			case "host":
+				config.blocks = append(config.blocks, sshConfigBlock{patterns: strings.Fields(value)})
+
+			case "match":
+				// Match criteria are not evaluated; the block never applies
+				config.blocks = append(config.blocks, sshConfigBlock{})
+
+			case "include":
+				for _, pattern := range strings.Fields(value) {
+					pattern = expandHome(pattern)
+
+					if !filepath.IsAbs(pattern) {
+						pattern = filepath.Join(sshHomeDir(), ".ssh", pattern)
+					}
+
+					files, _ := filepath.Glob(pattern)
+
+					for _, file := range files {
+						if err := config.load(file, depth + 1); err != nil {
+							return err
+						}
+					}
+				}
+
+			default:
+				b := &config.blocks[len(config.blocks) - 1]
+				b.options = append(b.options, [2]string{keyword, value})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return New(1802, err.Error())
+	}
+
+	return nil
+}
+
+//
+// Resolve returns the settings for alias; as with OpenSSH the first value
+// found for a keyword wins, except IdentityFile which accumulates
+func (config *SshConfig) Resolve(alias string) (host *SshConfigHost) {
+	host = &SshConfigHost{Alias: alias}
+
+	for _, b := range config.blocks {
+		if !sshConfigMatch(b.patterns, alias) {
+			continue
+		}
+
+		for _, o := range b.options {
+			switch o[0] {
+				case "hostname":
+					if host.HostName == "" {
+						host.HostName = o[1]
+					}
+				case "port":
+					if host.Port == 0 {
+						host.Port, _ = strconv.Atoi(o[1])
+					}
+				case "user":
+					if host.User == "" {
+						host.User = o[1]
+					}
+				case "identityfile":
+					host.IdentityFiles = append(host.IdentityFiles, expandHome(o[1]))
+				case "proxyjump":
+					if host.ProxyJump == "" {
+						host.ProxyJump = o[1]
+					}
+			}
+		}
+	}
+
+	if host.HostName == "" {
+		host.HostName = alias
+	} else {
+		host.HostName = strings.Replace(host.HostName, "%h", alias, -1)
+	}
+
+	if host.Port == 0 {
+		host.Port = 22
+	}
+
+	if host.ProxyJump == "none" {
+		host.ProxyJump = ""
+	}
+
+	return host
+}
+
+//
+// NewSshActionConfig resolves alias through the ssh_config file at configPath
+// (empty for ~/.ssh/config) and connects with its HostName, Port, User,
+// IdentityFile and ProxyJump settings. A non-empty user overrides the file;
+// passw is only offered to alias itself, never to its jump hosts.
+func NewSshActionConfig(alias string, configPath string, user string, passw string, su_passw string, verbose int) (sshAction *SshAction, err error) {
+	config, err := LoadSshConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	host := config.Resolve(alias)
+
+	if user == "" {
+		user = host.User
+	}
+
+	if user == "" {
+		return nil, New(1810, "no user for host '" + alias + "'")
+	}
+
+	var jumps []*ssh.Client
+	var dialer Dialer
+
+	if host.ProxyJump != "" {
+		jumps, err = config.dialJumps(host.ProxyJump, user)
+		if err != nil {
+			return nil, err
+		}
+
+		dialer = jumps[len(jumps) - 1]
+	}
+
+	sshAction, err = newSshAction(host.HostName, user, passw, su_passw, host.Port, verbose, dialer, sshAuthMethods(host.IdentityFiles, passw))
+	if err != nil {
+		closeJumps(jumps)
+		return nil, err
+	}
+
+	sshAction.jumps = jumps
+
+	return sshAction, nil
+}
+
+//
+// dialJumps connects to each hop of a ProxyJump list in turn, every hop
+// tunnelled through the previous one. Hops authenticate with their own
+// identity files only: the password of the target is never offered to them.
+func (config *SshConfig) dialJumps(proxyJump string, user string) (jumps []*ssh.Client, err error) {
+	hosts, err := config.jumpHosts(proxyJump, user)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range hosts {
+		var dialer Dialer
+
+		if len(jumps) > 0 {
+			dialer = jumps[len(jumps) - 1]
+		}
+
+		addr := net.JoinHostPort(h.HostName, strconv.Itoa(h.Port))
+
+		client, err := sshDial(addr, sshClientConfig(h.User, sshAuthMethods(h.IdentityFiles, "")), dialer)
+		if err != nil {
+			closeJumps(jumps)
+			return nil, New(1812, "jump host " + addr + ": " + err.Error())
+		}
+
+		jumps = append(jumps, client)
+	}
+
+	return jumps, nil
+}
+
+//
+// jumpHosts resolves the [user@]host[:port] hops of a ProxyJump list; a hop
+// without a user of its own, on the list or in the file, gets user. Hops
+// without an IdentityFile get the OpenSSH default identities.
+func (config *SshConfig) jumpHosts(proxyJump string, user string) (hosts []*SshConfigHost, err error) {
+	hops := strings.Split(proxyJump, ",")
+
+	if len(hops) > sshConfigMaxJumps {
+		return nil, New(1811, "too many ProxyJump hops")
+	}
+
+	for _, hop := range hops {
+		hop = strings.TrimSpace(hop)
+
+		hopUser := ""
+
+		if i := strings.LastIndex(hop, "@"); i >= 0 {
+			hopUser = hop[:i]
+			hop     = hop[i + 1:]
+		}
+
+		hopAlias := hop
+		hopPort  := 0
+
+		if h, p, err := net.SplitHostPort(hop); err == nil {
+			hopAlias   = h
+			hopPort, _ = strconv.Atoi(p)
+		}
+
+		h := config.Resolve(hopAlias)
+
+		if hopUser != "" {
+			h.User = hopUser
+		} else if h.User == "" {
+			h.User = user
+		}
+
+		if hopPort != 0 {
+			h.Port = hopPort
+		}
+
+		if len(h.IdentityFiles) == 0 {
+			for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+				h.IdentityFiles = append(h.IdentityFiles, filepath.Join(sshHomeDir(), ".ssh", name))
+			}
+		}
+
+		hosts = append(hosts, h)
+	}
+
+	return hosts, nil
+}
+
+/******************************************************************************************************************
+* helper functions
+*
+*/
+
+//
+// sshAuthMethods offers the readable, unencrypted identity files first and
+// falls back to password authentication
+func sshAuthMethods(identityFiles []string, passw string) (auth []ssh.AuthMethod) {
+	var signers []ssh.Signer
+
+	for _, file := range identityFiles {
+		pem, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		signer, err := ssh.ParsePrivateKey(pem)
+		if err != nil {
+			continue
+		}
+
+		signers = append(signers, signer)
+	}
+
+	if len(signers) > 0 {
+		auth = append(auth, ssh.PublicKeys(signers...))
+	}
+
+	if passw != "" {
+		auth = append(auth, ssh.Password(passw))
+	}
+
+	return auth
+}
+
+//
+//
+func closeJumps(jumps []*ssh.Client) {
+	for i := len(jumps) - 1; i >= 0; i-- {
+		jumps[i].Close()
+	}
+}
+
+//
+// sshConfigSplit returns the lower-cased keyword and the value of a config
+// line; both "Keyword value" and "Keyword=value" are accepted
+func sshConfigSplit(line string) (keyword string, value string) {
+	line = strings.TrimSpace(line)
+
+	if line == "" || line[0] == '#' {
+		return "", ""
+	}
+
+	i := strings.IndexAny(line, " \t=")
+	if i < 0 {
+		return strings.ToLower(line), ""
+	}
+
+	keyword = strings.ToLower(line[:i])
+	value   = strings.TrimSpace(line[i:])
+	value   = strings.TrimSpace(strings.TrimPrefix(value, "="))
+	value   = strings.Trim(value, "\"")
+
+	return keyword, value
+}
+
+//
+// sshConfigMatch applies Host patterns with OpenSSH semantics: any negated
+// pattern that matches rejects the host, otherwise one positive match is needed
+func sshConfigMatch(patterns []string, alias string) (bool) {
+	matched := false
+
+	for _, p := range patterns {
+		if strings.HasPrefix(p, "!") {
+			if ok, _ := filepath.Match(p[1:], alias); ok {
+				return false
+			}
+		} else if ok, _ := filepath.Match(p, alias); ok {
+			matched = true
+		}
+	}
+
+	return matched
+}
+
+//
+//
+func expandHome(path string) (string) {
+	if path == "~" {
+		return sshHomeDir()
+	}
+
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(sshHomeDir(), path[2:])
+	}
+
+	return path
+}
+
+//
+//
+func sshHomeDir() (string) {
+	if home := os.Getenv("HOME"); home != "" {
+		return home
+	}
+
+	return "."
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+
+package sshtool
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sshConfigText = `# options before the first Host apply to every host
+Compression yes
+
+Host gw-1 gw-2
+    HostName %h.example.net
+    User admin
+    IdentityFile ~/.ssh/gw
+
+Host gw-* !gw-lab
+    User operator
+    Port 2200
+    IdentityFile ~/.ssh/fleet
+    ProxyJump bastion
+
+Host gw-lab
+    HostName 10.0.0.9
+
+Host bastion
+    HostName bastion.example.net
+    User jump
+    ProxyJump none
+
+Match host gw-3
+    User never
+
+Include conf.d/*.conf
+
+Host *
+    User fallback
+`
+
+const sshConfigInclude = `Host extra
+    HostName=extra.example.net
+    Port = 2022
+`
+
+//
+// writeSshConfig puts text in $HOME/.ssh/config of a fresh HOME, with include
+// as $HOME/.ssh/conf.d/extra.conf
+func writeSshConfig(t *testing.T, text string, include string) (home string) {
+	home = t.TempDir()
+	t.Setenv("HOME", home)
+
+	if err := os.MkdirAll(filepath.Join(home, ".ssh", "conf.d"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(home, ".ssh", "config"), []byte(text), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(home, ".ssh", "conf.d", "extra.conf"), []byte(include), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return home
+}
+
+//
+//
+func TestSshConfigResolve(t *testing.T) {
+	home := writeSshConfig(t, sshConfigText, sshConfigInclude)
+
+	config, err := LoadSshConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ssh := filepath.Join(home, ".ssh")
+
+	for _, tt := range []SshConfigHost{
+		// the first value wins, IdentityFile accumulates
+		{"gw-1", "gw-1.example.net", 2200, "admin", []string{filepath.Join(ssh, "gw"), filepath.Join(ssh, "fleet")}, "bastion"},
+		{"gw-7", "gw-7", 2200, "operator", []string{filepath.Join(ssh, "fleet")}, "bastion"},
+		// negated pattern
+		{"gw-lab", "10.0.0.9", 22, "fallback", nil, ""},
+		{"bastion", "bastion.example.net", 22, "jump", nil, ""},
+		// Match blocks never apply
+		{"gw-3", "gw-3", 2200, "operator", []string{filepath.Join(ssh, "fleet")}, "bastion"},
+		// from the Include
+		{"extra", "extra.example.net", 2022, "fallback", nil, ""},
+		{"other", "other", 22, "fallback", nil, ""},
+	} {
+		if host := config.Resolve(tt.Alias); !reflect.DeepEqual(*host, tt) {
+			t.Errorf("Resolve(%q) = %+v, want %+v", tt.Alias, *host, tt)
+		}
+	}
+}
+
+//
+//
+func TestSshConfigJumpHosts(t *testing.T) {
+	home := writeSshConfig(t, "Host bastion\n    HostName bastion.example.net\n    User jump\n\nHost inner\n    IdentityFile ~/.ssh/inner\n", "")
+
+	config, err := LoadSshConfig(filepath.Join(home, ".ssh", "config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hosts, err := config.jumpHosts("bastion, ops@inner:2022,10.1.1.1", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ssh      := filepath.Join(home, ".ssh")
+	defaults := []string{filepath.Join(ssh, "id_ed25519"), filepath.Join(ssh, "id_ecdsa"), filepath.Join(ssh, "id_rsa")}
+
+	want := []SshConfigHost{
+		{"bastion", "bastion.example.net", 22, "jump", defaults, ""},
+		{"inner", "inner", 2022, "ops", []string{filepath.Join(ssh, "inner")}, ""},
+		{"10.1.1.1", "10.1.1.1", 22, "admin", defaults, ""},
+	}
+
+	if len(hosts) != len(want) {
+		t.Fatalf("jumpHosts() = %d hosts, want %d", len(hosts), len(want))
+	}
+
+	for i := range want {
+		if !reflect.DeepEqual(*hosts[i], want[i]) {
+			t.Errorf("hop %d = %+v, want %+v", i, *hosts[i], want[i])
+		}
+	}
+
+	if _, err := config.jumpHosts(strings.Repeat("bastion,", sshConfigMaxJumps) + "bastion", "admin"); errorNumber(err) != 1811 {
+		t.Errorf("too many hops: err = %v", err)
+	}
+}
+
+//
+//
+func TestLoadSshConfigErrors(t *testing.T) {
+	home := writeSshConfig(t, "Include ~/.ssh/config\n", "")
+
+	if _, err := LoadSshConfig(""); errorNumber(err) != 1800 {
+		t.Errorf("recursive Include: err = %v", err)
+	}
+
+	if _, err := LoadSshConfig(filepath.Join(home, "missing")); errorNumber(err) != 1801 {
+		t.Errorf("missing file: err = %v", err)
+	}
+}
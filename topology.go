@@ -0,0 +1,283 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"bytes"
+	"net"
+	"sort"
+)
+
+// InterfaceTopology is what belongs behind one interface for anti-spoofing:
+// the connected networks plus the routes whose gateway lies on the interface
+type InterfaceTopology struct {
+	IfName		string
+	Networks	[]net.IPNet			// connected and routed networks as found
+	Summary		[]net.IPNet			// Networks merged into the fewest CIDR blocks
+	External	bool				// the default route points out of this interface
+	Overlaps	[]TopologyOverlap
+}
+
+// TopologyOverlap is a network claimed by two interfaces
+type TopologyOverlap struct {
+	IfName		string
+	Other		string
+	Network		net.IPNet			// the more specific of the two overlapping blocks
+}
+
+type Topology []InterfaceTopology
+
+//
+// GetTopology computes the per interface topology from logical and routes
+// (see GetInterfaces and GetRoutes). Aliases, e.g. 'eth1:1', count as their
+// interface.
+func GetTopology(logical LogicalInterfaces, routes Routes) (topology Topology) {
+	byName := make(map[string]*InterfaceTopology)
+	var names []string
+
+	get := func(name string) (*InterfaceTopology) {
+		t, ok := byName[name]
+
+		if !ok {
+			t = &InterfaceTopology{IfName: name}
+			byName[name] = t
+			names = append(names, name)
+		}
+
+		return t
+	}
+
+	type connected struct {
+		name	string
+		net		net.IPNet
+	}
+
+	var conn []connected
+
+	for _, i := range logical {
+		if i.Addr == nil || i.Mask == nil {
+			continue
+		}
+
+		name  := topologyName(i.IfName)
+		ipnet := net.IPNet{IP: i.Addr.Mask(i.Mask), Mask: i.Mask}
+
+		t := get(name)
+		t.Networks = append(t.Networks, ipnet)
+
+		conn = append(conn, connected{name, ipnet})
+	}
+
+	for _, r := range routes {
+		if r.IPNet.IP == nil || r.IPNet.Mask == nil {
+			continue
+		}
+
+		// the interface holding the gateway; link-local IPv6 gateways only
+		// tell their interface through the route's device
+		name := topologyName(r.Dev)
+
+		if gw := net.ParseIP(r.Gateway); gw != nil {
+			for _, c := range conn {
+				if c.net.Contains(gw) {
+					name = c.name
+					break
+				}
+			}
+		}
+
+		if name == "" {
+			continue
+		}
+
+		t := get(name)
+
+		if ones, _ := r.IPNet.Mask.Size(); ones == 0 {
+			t.External = true
+			continue
+		}
+
+		t.Networks = append(t.Networks, r.IPNet)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		t := byName[name]
+		t.Summary = SummariseNetworks(t.Networks)
+
+		topology = append(topology, *t)
+	}
+
+	for i := range topology {
+		for j := i + 1; j < len(topology); j++ {
+			for _, a := range topology[i].Summary {
+				for _, b := range topology[j].Summary {
+					n, ok := overlap(a, b)
+					if !ok {
+						continue
+					}
+
+					topology[i].Overlaps = append(topology[i].Overlaps, TopologyOverlap{IfName: topology[i].IfName, Other: topology[j].IfName, Network: n})
+					topology[j].Overlaps = append(topology[j].Overlaps, TopologyOverlap{IfName: topology[j].IfName, Other: topology[i].IfName, Network: n})
+				}
+			}
+		}
+	}
+
+	return topology
+}
+
+//
+// SummariseNetworks returns the smallest set of CIDR blocks covering exactly
+// the union of networks: contained blocks are dropped and sibling blocks
+// merged into their parent
+func SummariseNetworks(networks []net.IPNet) (summary []net.IPNet) {
+	for _, n := range networks {
+		ip, bits := routeKey(n.IP)
+		if ip == nil {
+			continue
+		}
+
+		ones, maskBits := n.Mask.Size()
+		if maskBits != bits {
+			continue
+		}
+
+		mask := net.CIDRMask(ones, bits)
+
+		summary = append(summary, net.IPNet{IP: ip.Mask(mask), Mask: mask})
+	}
+
+	for {
+		sort.Slice(summary, func(i, j int) (bool) { return lessIPNet(summary[i], summary[j]) })
+
+		var merged []net.IPNet
+		changed := false
+
+		for _, n := range summary {
+			if len(merged) == 0 {
+				merged = append(merged, n)
+				continue
+			}
+
+			last := &merged[len(merged) - 1]
+
+			if containsNet(*last, n) {
+				changed = true
+				continue
+			}
+
+			if parent, ok := siblings(*last, n); ok {
+				*last   = parent
+				changed = true
+				continue
+			}
+
+			merged = append(merged, n)
+		}
+
+		summary = merged
+
+		if !changed {
+			break
+		}
+	}
+
+	return summary
+}
+
+/******************************************************************************************************************
+* helper functions
+*
+*/
+
+//
+//
+func topologyName(ifname string) (string) {
+	for i := range ifname {
+		if ifname[i] == ':' {
+			return ifname[:i]
+		}
+	}
+
+	return ifname
+}
+
+//
+// lessIPNet orders IPv4 before IPv6, then by address, then shorter prefix first
+func lessIPNet(a net.IPNet, b net.IPNet) (bool) {
+	if len(a.IP) != len(b.IP) {
+		return len(a.IP) < len(b.IP)
+	}
+
+	if c := bytes.Compare(a.IP, b.IP); c != 0 {
+		return c < 0
+	}
+
+	oa, _ := a.Mask.Size()
+	ob, _ := b.Mask.Size()
+
+	return oa < ob
+}
+
+//
+// containsNet reports whether b lies entirely within a
+func containsNet(a net.IPNet, b net.IPNet) (bool) {
+	oa, ba := a.Mask.Size()
+	ob, bb := b.Mask.Size()
+
+	return ba == bb && oa <= ob && a.Contains(b.IP)
+}
+
+//
+// siblings returns the parent of a and b when they are the two halves of it
+func siblings(a net.IPNet, b net.IPNet) (parent net.IPNet, ok bool) {
+	oa, ba := a.Mask.Size()
+	ob, bb := b.Mask.Size()
+
+	if ba != bb || oa != ob || oa == 0 || a.IP.Equal(b.IP) {
+		return parent, false
+	}
+
+	mask := net.CIDRMask(oa - 1, ba)
+
+	if !a.IP.Mask(mask).Equal(b.IP.Mask(mask)) {
+		return parent, false
+	}
+
+	return net.IPNet{IP: a.IP.Mask(mask), Mask: mask}, true
+}
+
+//
+// overlap returns the more specific of a and b when one contains the other
+func overlap(a net.IPNet, b net.IPNet) (net.IPNet, bool) {
+	if containsNet(a, b) {
+		return b, true
+	}
+
+	if containsNet(b, a) {
+		return a, true
+	}
+
+	return net.IPNet{}, false
+}
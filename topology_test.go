@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+
+package sshtool
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+//
+// testNetworks parses cidrs, keeping any host bits
+func testNetworks(cidrs ...string) (networks []net.IPNet) {
+	for _, c := range cidrs {
+		ip, ipnet, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+
+		networks = append(networks, net.IPNet{IP: ip, Mask: ipnet.Mask})
+	}
+
+	return networks
+}
+
+//
+//
+func networkStrings(networks []net.IPNet) (s []string) {
+	for _, n := range networks {
+		s = append(s, n.String())
+	}
+
+	return s
+}
+
+//
+//
+func TestSummariseNetworks(t *testing.T) {
+	for _, tt := range []struct {
+		name		string
+		networks	[]string
+		want		[]string
+	}{
+		{"empty", nil, nil},
+		{"siblings", []string{"10.0.0.128/25", "10.0.0.0/25"}, []string{"10.0.0.0/24"}},
+		{"siblings of siblings", []string{"10.0.3.0/24", "10.0.1.0/24", "10.0.0.0/24", "10.0.2.0/24"}, []string{"10.0.0.0/22"}},
+		{"adjacent but not siblings", []string{"10.0.1.0/24", "10.0.2.0/24"}, []string{"10.0.1.0/24", "10.0.2.0/24"}},
+		{"contained", []string{"10.0.5.0/24", "10.0.0.1/32", "10.0.0.0/16"}, []string{"10.0.0.0/16"}},
+		{"duplicates", []string{"10.0.0.0/24", "10.0.0.0/24"}, []string{"10.0.0.0/24"}},
+		{"host bits", []string{"10.0.0.1/24"}, []string{"10.0.0.0/24"}},
+		{"merge then contain", []string{"10.0.0.0/25", "10.0.0.128/25", "10.0.1.0/24", "10.0.0.64/26"}, []string{"10.0.0.0/23"}},
+		{"IPv6", []string{"2001:db8:0:0:8000::/65", "2001:db8::/65", "2001:db8:1::/64"}, []string{"2001:db8::/64", "2001:db8:1::/64"}},
+		{"IPv4 first", []string{"2001:db8::/64", "192.168.1.0/24", "10.0.0.0/8"}, []string{"10.0.0.0/8", "192.168.1.0/24", "2001:db8::/64"}},
+	} {
+		if got := networkStrings(SummariseNetworks(testNetworks(tt.networks...))); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: SummariseNetworks(%q) = %q, want %q", tt.name, tt.networks, got, tt.want)
+		}
+	}
+
+	// a 16 byte IPv4 address with a 4 byte mask, as net.ParseIP gives it
+	mixed := []net.IPNet{{IP: net.ParseIP("10.0.0.0"), Mask: net.CIDRMask(24, 32)}}
+
+	if got := networkStrings(SummariseNetworks(mixed)); !reflect.DeepEqual(got, []string{"10.0.0.0/24"}) {
+		t.Errorf("SummariseNetworks(16 byte IPv4) = %q", got)
+	}
+}
+
+//
+//
+func TestGetTopology(t *testing.T) {
+	logical := LogicalInterfaces{
+		testLogical("eth0", "192.168.1.1/24"),
+		testLogical("eth1", "10.0.0.1/25"),
+		testLogical("eth1:1", "10.0.0.129/25"),
+		testLogical("eth2", "172.16.0.1/24"),
+		testLogical("eth2", "2001:db8:2::1/64"),
+	}
+
+	routes := Routes{
+		testRoute("0.0.0.0/0", "192.168.1.254", "eth0", 0),
+		testRoute("10.5.0.0/16", "10.0.0.2", "eth1", 0),
+		// also behind eth2: an overlap
+		testRoute("10.5.1.0/24", "172.16.0.2", "eth2", 0),
+		// the gateway's address, not the device, decides the interface
+		testRoute("10.6.0.0/16", "10.0.0.130", "eth0", 0),
+		// link-local gateways only tell their interface through the device
+		testRoute("2001:db8:20::/48", "fe80::1", "eth2", 0),
+		testRoute("::/0", "fe80::fffe", "eth0", 0),
+	}
+
+	type result struct {
+		IfName		string
+		Summary		[]string
+		External	bool
+		Overlaps	[]string
+	}
+
+	var got []result
+
+	for _, i := range GetTopology(logical, routes) {
+		r := result{IfName: i.IfName, Summary: networkStrings(i.Summary), External: i.External}
+
+		for _, o := range i.Overlaps {
+			r.Overlaps = append(r.Overlaps, o.Other + " " + o.Network.String())
+		}
+
+		got = append(got, r)
+	}
+
+	want := []result{
+		{"eth0", []string{"192.168.1.0/24"}, true, nil},
+		{"eth1", []string{"10.0.0.0/24", "10.5.0.0/16", "10.6.0.0/16"}, false, []string{"eth2 10.5.1.0/24"}},
+		{"eth2", []string{"10.5.1.0/24", "172.16.0.0/24", "2001:db8:2::/64", "2001:db8:20::/48"}, false, []string{"eth1 10.5.1.0/24"}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetTopology() =\n%+v\nwant\n%+v", got, want)
+	}
+}
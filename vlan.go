@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"fmt"
+	"strings"
+	"github.com/mikejac/ssh.golang/parser"
+)
+
+// The VLAN types are defined, and parsed, in the parser package
+type NetworkVLAN	= parser.NetworkVLAN
+type VLANs		= parser.VLANs
+
+//
+// 3500
+func (sshAction *SshAction) GetVLANs() (vlans VLANs, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVLANs(): begin\n") }
+
+	var result		string
+	var warnings	parser.Warnings
+
+	switch sshAction.platform {
+		case PlatformGAiA:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVLANs(): PlatformGAiA\n") }
+
+			if result, err = sshAction.expertExecute("cat /proc/net/vlan/config 2>/dev/null", 10); err != nil {
+				return nil, New(3502, err.Error())
+			}
+
+			if vlans, warnings = parser.ParseProcVlanConfig(result); len(vlans) > 0 {
+				break
+			}
+
+			// /proc/net/vlan is root only; CLISH knows the configured VLANs as well
+			if result, err = sshAction.execute("show configuration interface", 10); err != nil {
+				return nil, New(3503, err.Error())
+			}
+
+			var clish parser.Warnings
+
+			vlans, clish = parser.ParseClishVlans(result)
+			warnings     = append(warnings, clish...)
+
+		case PlatformGaiaEmbedded:
+			fallthrough
+
+		case PlatformScalable:
+			fallthrough
+
+		case PlatformSplatCPSHELL:
+			fallthrough
+
+		case PlatformExpert:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVLANs(): Linux platform\n") }
+
+			if result, err = sshAction.expertExecute("cat /proc/net/vlan/config 2>/dev/null", 10); err != nil {
+				return nil, New(3502, err.Error())
+			}
+
+			// missing without the 8021q module and unreadable for non-root users;
+			// the caller then has to fall back to the interface names
+			if strings.TrimSpace(result) == "" {
+				return nil, New(3504, "no vlan table")
+			}
+
+			vlans, warnings = parser.ParseProcVlanConfig(result)
+
+		case PlatformIPSO:
+			fallthrough
+
+		case PlatformXBM:
+			return nil, New(3500, "not supported on platform")
+
+		default:
+			fmt.Printf("SshAction::GetVLANs(): unknown platform\n")
+			return nil, New(3501, "platform unknown")
+	}
+
+	sshAction.warn(warnings)
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVLANs(): end; %d vlans\n", len(vlans)) }
+
+	return vlans, nil
+}
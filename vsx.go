@@ -0,0 +1,236 @@
+/*
+ * Copyright (c) 2016 Michael Jacobsen (github.com/mikejac)
+ *
+ * This file is part of ssh.golang.
+ *
+ * ssh.golang is free software: you can redistribute
+ * it and/or modify it under the terms of the GNU General Public License
+ * as published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * ssh.golang is distributed in the hope that it will
+ * be useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+ * of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with ssh.golang.  If not,
+ * see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package sshtool
+
+import (
+	"fmt"
+	"strings"
+	"strconv"
+	"github.com/mikejac/ssh.golang/parser"
+)
+
+// The VSX types are defined, and parsed, in the parser package
+type VirtualSystem	= parser.VirtualSystem
+type VirtualSystems	= parser.VirtualSystems
+
+// VSResult is what a collector returned when run inside one virtual system
+type VSResult struct {
+	VS			VirtualSystem
+	Result		interface{}
+	Err			error
+}
+
+// VSResults holds collector results keyed by virtual system ID
+type VSResults map[int]*VSResult
+
+//
+// GetVirtualSystems lists VS0 followed by the virtual devices from 'vsx stat -v'
+func (sshAction *SshAction) GetVirtualSystems() (vss VirtualSystems, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVirtualSystems(): begin\n") }
+
+	var result string
+
+	switch sshAction.platform {
+		case PlatformGAiA:
+			fallthrough
+
+		case PlatformScalable:
+			fallthrough
+
+		case PlatformSplatCPSHELL:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVirtualSystems(): PlatformGAiA, PlatformScalable or PlatformSplatCPSHELL\n") }
+
+			if sshAction.expertEnter() == nil {
+				result, err = sshAction.execute("vsx stat -v 2>&1", 30)
+				if err != nil {
+					if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVirtualSystems(): unable to execute 'vsx stat -v 2>&1'\n") }
+				}
+
+				sshAction.expertExit()
+			}
+
+		case PlatformExpert:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVirtualSystems(): PlatformExpert\n") }
+
+			result, err = sshAction.execute("vsx stat -v 2>&1", 30)
+			if err != nil {
+				if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVirtualSystems(): unable to execute 'vsx stat -v 2>&1'\n") }
+			}
+
+		case PlatformGaiaEmbedded:
+			fallthrough
+
+		case PlatformIPSO:
+			fallthrough
+
+		case PlatformXBM:
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVirtualSystems(): platform without VSX\n") }
+
+			return nil, New(6000, "platform does not support vsx")
+
+		default:
+			fmt.Printf("SshAction::GetVirtualSystems(): unknown platform\n")
+			return nil, New(6001, "platform unknown")
+	}
+
+	if err != nil {
+		return nil, New(6002, err.Error())
+	}
+
+	if !strings.Contains(result, "VSX Gateway Status") {
+		return nil, New(6003, "not a vsx gateway")
+	}
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVirtualSystems(): lines = %q\n", strings.Split(result, "\n")) }
+
+	vss, warnings := parser.ParseVsxStat(result)
+
+	sshAction.warn(warnings)
+
+	if sshAction.verbose >= 1 {
+		for _, vs := range vss {
+			fmt.Printf("SshAction::GetVirtualSystems(): id = %d, type = '%s', name = '%s'\n", vs.ID, vs.Type, vs.Name)
+		}
+	}
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::GetVirtualSystems(): end\n") }
+
+	return vss, nil
+}
+
+//
+// SwitchVS moves the expert shell into the context of virtual system id.
+// Only valid while connected in Expert-mode; see ForEachVS otherwise.
+func (sshAction *SshAction) SwitchVS(id int) (err error) {
+	if sshAction.platform != PlatformExpert {
+		return New(6100, "vsenv requires expert-mode")
+	}
+
+	return sshAction.vsenv(id)
+}
+
+//
+// ForEachVS runs collect inside the context of every virtual system in vss and
+// returns to VS0 afterwards. Collectors see the session as Expert-mode while
+// they run, so e.g. ForEachVS(vss, func(a *SshAction) (interface{}, error) { return a.GetRoutes() })
+// collects the routing table of each virtual system.
+func (sshAction *SshAction) ForEachVS(vss VirtualSystems, collect func(*SshAction) (interface{}, error)) (results VSResults, err error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::ForEachVS(): begin\n") }
+
+	platform := sshAction.platform
+
+	switch platform {
+		case PlatformGAiA:
+			fallthrough
+
+		case PlatformScalable:
+			fallthrough
+
+		case PlatformSplatCPSHELL:
+			if err = sshAction.expertEnter(); err != nil {
+				return nil, New(6200, err.Error())
+			}
+
+			sshAction.platform = PlatformExpert
+
+		case PlatformExpert:
+
+		default:
+			return nil, New(6201, "platform does not support vsx")
+	}
+
+	results = make(VSResults)
+
+	for _, vs := range vss {
+		r := &VSResult{VS: vs}
+
+		if r.Err = sshAction.vsenv(vs.ID); r.Err == nil {
+			r.Result, r.Err = collect(sshAction)
+		}
+
+		if r.Err != nil {
+			if sshAction.verbose > 0 { fmt.Printf("SshAction::ForEachVS(): vs %d: %s\n", vs.ID, r.Err.Error()) }
+		}
+
+		results[vs.ID] = r
+	}
+
+	err = sshAction.vsenv(0)
+
+	if sshAction.platform != platform {
+		sshAction.platform = platform
+		sshAction.expertExit()
+	}
+
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::ForEachVS(): end\n") }
+
+	return results, err
+}
+
+//
+//
+func (sshAction *SshAction) GetInterfacesPerVS(vss VirtualSystems) (results VSResults, err error) {
+	return sshAction.ForEachVS(vss, func(a *SshAction) (interface{}, error) {
+		return a.GetInterfaces()
+	})
+}
+
+//
+//
+func (sshAction *SshAction) GetRoutesPerVS(vss VirtualSystems) (results VSResults, err error) {
+	return sshAction.ForEachVS(vss, func(a *SshAction) (interface{}, error) {
+		return a.GetRoutes()
+	})
+}
+
+//
+// ByName returns the result for the virtual system called name
+func (results VSResults) ByName(name string) (*VSResult) {
+	for _, r := range results {
+		if r.VS.Name == name {
+			return r
+		}
+	}
+
+	return nil
+}
+
+//
+// vsenv switches context; the prompt changes to carry the VS ID, so it is
+// located again afterwards
+func (sshAction *SshAction) vsenv(id int) (error) {
+	if sshAction.verbose > 0 { fmt.Printf("SshAction::vsenv(): id = %d\n", id) }
+
+	sshAction.in.Write([]byte("vsenv " + strconv.Itoa(id) + " >/dev/null 2>&1\n"))
+
+	if err := sshAction.waitfor(); err != nil {
+		if sshAction.verbose > 0 { fmt.Println("SshAction::vsenv(): " + err.Error()) }
+		return New(6101, "failed to locate prompt")
+	}
+
+	// expert prompts carry the context, e.g. '[Expert@gw-vsx:1]# '
+	if !strings.Contains(sshAction.currentPrompt, ":" + strconv.Itoa(id) + "]") {
+		return New(6102, "failed to switch to vs " + strconv.Itoa(id))
+	}
+
+	return nil
+}